@@ -988,12 +988,13 @@ func (i *InputObjectExtension) IsTypeExtension() {}
 // GraphQL implementations that support the type system definition language must provide
 // the @deprecated directive if representing deprecated portions of the schema.
 type DirectiveDefinition struct {
-	Kind      string                  `json:"kind"`
-	Desc      *StringValue            `json:"desc"`
-	Name      *Name                   `json:"name"`
-	Arguments []*InputValueDefinition `json:"arguments"`
-	Locations []string                `json:"locations"`
-	Loc       errors.Location         `json:"loc"`
+	Kind       string                  `json:"kind"`
+	Desc       *StringValue            `json:"desc"`
+	Name       *Name                   `json:"name"`
+	Arguments  []*InputValueDefinition `json:"arguments"`
+	Repeatable bool                    `json:"repeatable"`
+	Locations  []string                `json:"locations"`
+	Loc        errors.Location         `json:"loc"`
 }
 
 func (d *DirectiveDefinition) IsDefinition() {}
@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"net/http"
+	"sync"
+)
+
+// forOperation returns the Context of one operation of the batch of c. The values set on it are
+// its own, while those of c, like the loaders of the request, are shared by the whole batch.
+func (c *Context) forOperation() *Context {
+	ctx := *c
+	ctx.keys = make(map[interface{}]interface{})
+	ctx.mu = new(sync.RWMutex)
+	ctx.parent = c
+	ctx.Error = nil
+	return &ctx
+}
+
+// executeBatch runs the operations sent together in one request and writes their responses, in the
+// same order. Queries run concurrently, while a mutation waits for the operations before it to finish
+// and the operations after it wait for the mutation.
+func (h *Handler) executeBatch(ctx *Context, params []execution.Params) {
+	if len(params) == 0 {
+		ctx.ServerError("a batch must contain at least one operation", http.StatusBadRequest)
+		return
+	}
+	if ctx.MaxBatchSize > 0 && len(params) > ctx.MaxBatchSize {
+		ctx.ServerError(fmt.Sprintf("a batch must not contain more than %d operations", ctx.MaxBatchSize), http.StatusBadRequest)
+		return
+	}
+	schemabuilder.WithLoaders(ctx)
+
+	ops := make([]*operation, len(params))
+	var wg sync.WaitGroup
+	for i, param := range params {
+		op := h.newOperation(ctx.forOperation(), param)
+		ops[i] = op
+		if op.response != nil {
+			continue
+		}
+		if op.operationType == ast.Mutation {
+			wg.Wait()
+			op.execute(h, false)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			op.execute(h, false)
+		}()
+	}
+	wg.Wait()
+
+	responses := make([]*Response, len(ops))
+	for i, op := range ops {
+		op.finish()
+		ctx.Error = append(ctx.Error, op.ctx.Error...)
+		h.present(op.ctx, op.response)
+		responses[i] = op.response
+	}
+	responseJSON, err := json.Marshal(responses)
+	if err != nil {
+		ctx.ServerError(err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ctx.Writer.Header().Set("Content-Type", responseMediaType(ctx.Request))
+	if ctx.Writer.status == 0 {
+		ctx.Writer.WriteHeader(http.StatusOK)
+	}
+	ctx.Writer.Write(responseJSON)
+}
@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"container/list"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/validation"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// lru is a size bounded map which evicts its least recently used key first.
+type lru struct {
+	size  int
+	mu    sync.Mutex
+	ll    *list.List
+	items map[interface{}]*list.Element
+}
+
+type lruEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+func newLRU(size int) *lru {
+	if size < 1 {
+		panic("graphql: cache size must be positive")
+	}
+	return &lru{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[interface{}]*list.Element),
+	}
+}
+
+func (c *lru) get(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*lruEntry).value, true
+	}
+	return nil, false
+}
+
+func (c *lru) add(key, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*lruEntry).value = value
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value})
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lru) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// QueryCache is a LRU cache of parsed and validated queries, keyed by query text and
+// operation name. Set it on Handler.Cache to skip parsing the queries clients send over
+// and over.
+//
+// Queries are checked against the limits in force when they are cached.
+type QueryCache struct {
+	lru    *lru
+	hits   uint64
+	misses uint64
+}
+
+// QueryCacheStats reports how well a QueryCache is doing.
+type QueryCacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Len    int
+}
+
+type queryKey struct {
+	query         string
+	operationName string
+}
+
+type cachedQuery struct {
+	doc *internal.Document
+	// operationType and selectionSet are only set for queries without variables,
+	// their selection set is the same for every request. It is shared by the
+	// requests running concurrently, which must only read it, arguments included.
+	operationType ast.OperationType
+	selectionSet  *internal.SelectionSet
+}
+
+// NewQueryCache returns a QueryCache holding at most size queries.
+func NewQueryCache(size int) *QueryCache {
+	return &QueryCache{lru: newLRU(size)}
+}
+
+// Stats returns the number of hits, misses and cached queries so far.
+func (c *QueryCache) Stats() QueryCacheStats {
+	return QueryCacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+		Len:    c.lru.len(),
+	}
+}
+
+func (c *QueryCache) get(key queryKey) (*cachedQuery, bool) {
+	if q, ok := c.lru.get(key); ok {
+		atomic.AddUint64(&c.hits, 1)
+		return q.(*cachedQuery), true
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil, false
+}
+
+// prepare parses and validates query, then binds vars to the selected operation,
+// reusing the work done for previous requests when the handler has a cache.
+func (h *Handler) prepare(query, operationName string, vars map[string]interface{},
+	limits execution.Limits, tracing *execution.Tracing) (ast.OperationType, *internal.SelectionSet, error) {
+	parse := func() (*internal.Document, error) {
+		defer tracing.TraceParsing(time.Now())
+		return internal.Parse(query)
+	}
+	// cached documents are already known to be valid
+	apply := func(doc *internal.Document, validate bool) (ast.OperationType, *internal.SelectionSet, error) {
+		defer tracing.TraceValidation(time.Now())
+		if validate {
+			// the limits are cheap to check, and bound the work of validation
+			if err := execution.CheckLimits(doc, operationName, limits); err != nil {
+				return "", nil, err
+			}
+			if errs := validation.Validate(h.Schema, doc); len(errs) > 0 {
+				return "", nil, errs
+			}
+		}
+		return execution.ApplySelectionSetWithLimits(h.Schema, doc, operationName, vars, limits)
+	}
+
+	if h.Cache == nil {
+		doc, err := parse()
+		if err != nil {
+			return "", nil, err
+		}
+		return apply(doc, true)
+	}
+
+	key := queryKey{query: query, operationName: operationName}
+	if cached, ok := h.Cache.get(key); ok {
+		if cached.selectionSet != nil {
+			return cached.operationType, cached.selectionSet, nil
+		}
+		return apply(cached.doc, false)
+	}
+
+	doc, err := parse()
+	if err != nil {
+		return "", nil, err
+	}
+	operationType, selectionSet, err := apply(doc, true)
+	if err != nil {
+		return "", nil, err
+	}
+	cached := &cachedQuery{doc: doc}
+	if !hasVariables(doc) {
+		cached.operationType, cached.selectionSet = operationType, selectionSet
+	}
+	h.Cache.lru.add(key, cached)
+	return operationType, selectionSet, nil
+}
+
+// hasVariables reports whether binding the variables of a request can change the selection set of doc.
+func hasVariables(doc *internal.Document) bool {
+	for _, op := range doc.Operations {
+		if len(op.Vars) > 0 {
+			return true
+		}
+	}
+	for _, fragment := range doc.Fragments {
+		if len(fragment.VariableDefinitions) > 0 {
+			return true
+		}
+	}
+	return false
+}
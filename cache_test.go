@@ -0,0 +1,106 @@
+package graphql_test
+
+import (
+	"fmt"
+	"github.com/shyptr/graphql"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestQueryCache(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("echo", func(args struct {
+		Value string `graphql:"value"`
+	}) string {
+		return args.Value
+	}, "")
+	handler := &graphql.Handler{
+		Schema:   build.MustBuild(),
+		Executor: &execution.Executor{},
+		Cache:    graphql.NewQueryCache(2),
+	}
+
+	post := func(body string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		res, err := ioutil.ReadAll(w.Body)
+		assert.NoError(t, err)
+		return string(res)
+	}
+
+	// queries with variables are bound again on every request
+	const withVars = `{"query": "query Q($v: String!) { echo(value: $v) }", "variables": {"v": "%s"}}`
+	assert.JSONEq(t, `{"data": {"echo": "a"}}`, post(fmt.Sprintf(withVars, "a")))
+	assert.JSONEq(t, `{"data": {"echo": "b"}}`, post(fmt.Sprintf(withVars, "b")))
+	assert.Equal(t, graphql.QueryCacheStats{Hits: 1, Misses: 1, Len: 1}, handler.Cache.Stats())
+
+	assert.JSONEq(t, `{"data": {"echo": "c"}}`, post(`{"query": "{ echo(value: \"c\") }"}`))
+	assert.JSONEq(t, `{"data": {"echo": "c"}}`, post(`{"query": "{ echo(value: \"c\") }"}`))
+	assert.Equal(t, graphql.QueryCacheStats{Hits: 2, Misses: 2, Len: 2}, handler.Cache.Stats())
+
+	// the least recently used query is evicted
+	assert.JSONEq(t, `{"data": {"echo": "d"}}`, post(`{"query": "{ echo(value: \"d\") }"}`))
+	assert.JSONEq(t, `{"data": {"echo": "c"}}`, post(`{"query": "{ echo(value: \"c\") }"}`))
+	assert.Equal(t, graphql.QueryCacheStats{Hits: 3, Misses: 3, Len: 2}, handler.Cache.Stats())
+
+	// failed queries are not cached
+	post(`{"query": "{ missing }"}`)
+	post(`{"query": "{ missing }"}`)
+	assert.Equal(t, graphql.QueryCacheStats{Hits: 3, Misses: 5, Len: 2}, handler.Cache.Stats())
+}
+
+func TestQueryCacheConcurrentRequests(t *testing.T) {
+	type Item struct {
+		ID schemabuilder.Id `graphql:"id"`
+	}
+	type Page struct {
+		*schemabuilder.PaginationInfo
+		Items []Item
+	}
+	build := schemabuilder.NewSchema()
+	build.Object("Item", Item{})
+	schemabuilder.RelayKey(Item{}, "id")
+	build.Object("Page", Page{})
+	// the connection arguments are added to the arguments of every resolution
+	build.Query().FieldFunc("items", func(args struct {
+		*schemabuilder.ConnectionArgs
+	}) *Page {
+		return &Page{
+			PaginationInfo: &schemabuilder.PaginationInfo{TotalCount: 3},
+			Items:          []Item{{ID: schemabuilder.Id{Value: 1}}, {ID: schemabuilder.Id{Value: 2}}, {ID: schemabuilder.Id{Value: 3}}}[:*args.First],
+		}
+	}, "", schemabuilder.RelayConnection)
+	handler := &graphql.Handler{
+		Schema:   build.MustBuild(),
+		Executor: &execution.Executor{},
+		Cache:    graphql.NewQueryCache(1),
+	}
+	post := func() string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "{ items(first: 1) { totalCount } }"}`)))
+		return w.Body.String()
+	}
+	// the selection set is cached by the first request, and shared by the next ones
+	assert.JSONEq(t, `{"data": {"items": {"totalCount": 3}}}`, post())
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = post()
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		assert.JSONEq(t, `{"data": {"items": {"totalCount": 3}}}`, result)
+	}
+	assert.Equal(t, uint64(10), handler.Cache.Stats().Hits)
+}
@@ -0,0 +1,156 @@
+// Package client executes GraphQL operations against servers, like those served by the graphql
+// package, over HTTP and, for subscriptions, over websockets with the graphql-ws protocol.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/shyptr/graphql/errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// Client sends operations to the GraphQL endpoint at URL.
+type Client struct {
+	URL string
+	// HTTPClient sends the requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+	// Header is sent with every request and websocket handshake, for example to authenticate
+	Header http.Header
+	// Dialer opens the websockets of subscriptions, websocket.DefaultDialer when nil
+	Dialer *websocket.Dialer
+	// InitPayload is the payload of the connection_init message of subscriptions
+	InitPayload map[string]interface{}
+}
+
+// New returns a client of the endpoint at url.
+func New(url string) *Client {
+	return &Client{URL: url}
+}
+
+// Request is an operation to execute. The variables are encoded to JSON, but for the Upload
+// values, which are sent as files.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Response is the response of a server to an operation, whose data is yet to be decoded.
+type Response struct {
+	Data       json.RawMessage        `json:"data,omitempty"`
+	Errors     errors.MultiError      `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Decode decodes the data of the response into data, like json.Unmarshal, and returns the errors
+// of the response. The data of the fields which failed are left as they are.
+func (r *Response) Decode(data interface{}) error {
+	if data != nil && len(r.Data) > 0 {
+		if err := json.Unmarshal(r.Data, data); err != nil {
+			return fmt.Errorf("graphql: decoding data: %v", err)
+		}
+	}
+	if len(r.Errors) > 0 {
+		return r.Errors
+	}
+	return nil
+}
+
+// HTTPError is the error of a request which the server answered without a GraphQL response,
+// like a request which it failed to read.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("graphql: server responded %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// Do executes req over HTTP and decodes the data of its response into data, which is usually a
+// pointer to a struct whose json tags are the names of the fields of the operation. The errors
+// of the response are returned as an errors.MultiError, along with the data of the other fields.
+func (c *Client) Do(ctx context.Context, req *Request, data interface{}) error {
+	res, err := c.Execute(ctx, req)
+	if err != nil {
+		return err
+	}
+	return res.Decode(data)
+}
+
+// Query executes query with variables, see Do.
+func (c *Client) Query(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	return c.Do(ctx, &Request{Query: query, Variables: variables}, data)
+}
+
+// Execute executes req over HTTP and returns the response of the server. The request is sent as
+// JSON, or as a multipart request when its variables hold files.
+func (c *Client) Execute(ctx context.Context, req *Request) (*Response, error) {
+	body, contentType, err := encodeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, c.URL, body)
+	if err != nil {
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
+		}
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	for key, values := range c.Header {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("Accept", "application/graphql-response+json, application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpRes, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+	return readResponse(httpRes)
+}
+
+// encodeRequest returns the body of the request of req and its content type.
+func encodeRequest(req *Request) (io.Reader, string, error) {
+	variables, uploads := extractUploads(req.Variables, "variables")
+	if len(uploads) > 0 {
+		operation := *req
+		operation.Variables = variables.(map[string]interface{})
+		return encodeMultipart(&operation, uploads)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(body), "application/json", nil
+}
+
+// readResponse reads the GraphQL response of res. Servers answer with an error status the
+// operations they can't execute, the response then still holds their errors.
+func readResponse(res *http.Response) (*Response, error) {
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == "application/json" || mediaType == "application/graphql-response+json" {
+		var response Response
+		if err := json.Unmarshal(body, &response); err == nil && (response.Data != nil || response.Errors != nil) {
+			return &response, nil
+		}
+	}
+	return nil, &HTTPError{StatusCode: res.StatusCode, Body: string(bytes.TrimSpace(body))}
+}
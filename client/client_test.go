@@ -0,0 +1,175 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/shyptr/graphql"
+	"github.com/shyptr/graphql/client"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"gocloud.dev/pubsub"
+	"gocloud.dev/pubsub/mempubsub"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Item struct {
+	ID   string `graphql:"id"`
+	Name string `graphql:"name"`
+}
+
+func buildSchema(t *testing.T) *schemabuilder.Schema {
+	build := schemabuilder.NewSchema()
+	build.Object("Item", Item{})
+	build.Query().FieldFunc("item", func(args struct {
+		ID string `graphql:"id"`
+	}) (*Item, error) {
+		if args.ID == "0" {
+			return nil, fmt.Errorf("no item %s", args.ID)
+		}
+		return &Item{ID: args.ID, Name: "item " + args.ID}, nil
+	}, "")
+	build.Mutation().FieldFunc("upload", func(args struct {
+		File  schemabuilder.Upload   `graphql:"file"`
+		Files []schemabuilder.Upload `graphql:"files"`
+	}) []string {
+		var uploaded []string
+		for _, file := range append([]schemabuilder.Upload{args.File}, args.Files...) {
+			content, err := ioutil.ReadAll(file.File)
+			assert.NoError(t, err)
+			file.File.Seek(0, io.SeekStart)
+			uploaded = append(uploaded, fmt.Sprintf("%s:%d:%s", file.Filename, file.Size, content))
+		}
+		return uploaded
+	}, "")
+	build.Subscription().FieldFunc("event", func(source schemabuilder.Subscription) string {
+		return string(source.Payload)
+	}, "")
+	return build
+}
+
+func TestClient_Do(t *testing.T) {
+	server := httptest.NewServer(graphql.HTTPHandler(buildSchema(t).MustBuild()))
+	defer server.Close()
+	c := client.New(server.URL)
+	ctx := context.Background()
+
+	var data struct {
+		Item struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"item"`
+		Other *Item `json:"other"`
+	}
+	err := c.Query(ctx, `query ($id: String!) { item(id: $id) { id name } other: item(id: "0") { id } }`,
+		map[string]interface{}{"id": "1"}, &data)
+	assert.Equal(t, "1", data.Item.ID)
+	assert.Equal(t, "item 1", data.Item.Name)
+	assert.Nil(t, data.Other)
+	if errs, ok := err.(errors.MultiError); assert.True(t, ok, "%v", err) && assert.Len(t, errs, 1) {
+		assert.Equal(t, "no item 0", errs[0].Message)
+		assert.Equal(t, []interface{}{"other"}, errs[0].Path)
+	}
+
+	// operations which can't be executed still get a GraphQL response
+	res, err := c.Execute(ctx, &client.Request{Query: `{ nope }`})
+	if assert.NoError(t, err) && assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, `Cannot query field "nope" on type "Query".`, res.Errors[0].Message)
+		assert.Equal(t, []errors.Location{{Line: 1, Column: 3}}, res.Errors[0].Locations)
+	}
+
+	c.Header = http.Header{"Content-Type": {"ignored"}}
+	err = c.Do(ctx, &client.Request{Query: `query a { item(id: "1") { id } } query b { item(id: "2") { id } }`, OperationName: "b"}, &data)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", data.Item.ID)
+}
+
+func TestClient_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not here", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := client.New(server.URL).Query(context.Background(), `{ item(id: "1") { id } }`, nil, nil)
+	assert.Equal(t, &client.HTTPError{StatusCode: http.StatusNotFound, Body: "not here"}, err)
+	assert.EqualError(t, err, "graphql: server responded 404 Not Found: not here")
+}
+
+func TestClient_Uploads(t *testing.T) {
+	server := httptest.NewServer(graphql.HTTPHandler(buildSchema(t).MustBuild()))
+	defer server.Close()
+
+	one := &client.Upload{File: strings.NewReader("one"), Filename: "one.txt"}
+	var data struct {
+		Upload []string `json:"upload"`
+	}
+	err := client.New(server.URL).Query(context.Background(),
+		`mutation ($file: Upload!, $files: [Upload!]) { upload(file: $file, files: $files) }`,
+		map[string]interface{}{
+			"file": one,
+			"files": []*client.Upload{
+				{File: strings.NewReader("two"), Filename: "two.txt", ContentType: "text/plain"},
+				one,
+			},
+		}, &data)
+	assert.NoError(t, err)
+	// the same upload is only sent once, at both paths
+	assert.Equal(t, []string{"one.txt:3:one", "two.txt:3:two", "one.txt:3:one"}, data.Upload)
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	topic := mempubsub.NewTopic()
+	defer topic.Shutdown(context.Background())
+	subscription := mempubsub.NewSubscription(topic, time.Minute)
+	handler, start := graphql.HTTPSubHandler(buildSchema(t).MustBuild(), subscription)
+	start()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	c := client.New(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, err := c.Subscribe(ctx, &client.Request{Query: `subscription { event }`})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the events sent before the server starts the subscription are lost, so they are sent until one is received
+	received := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-received:
+				return
+			case <-time.After(10 * time.Millisecond):
+				topic.Send(context.Background(), &pubsub.Message{Body: []byte("hello")})
+			}
+		}
+	}()
+	var data struct {
+		Event string `json:"event"`
+	}
+	assert.NoError(t, s.Next(&data))
+	close(received)
+	assert.Equal(t, "hello", data.Event)
+
+	// the events received before are still read
+	cancel()
+	for err = s.Next(&data); err == nil; err = s.Next(&data) {
+	}
+	assert.Equal(t, context.Canceled, err)
+	assert.NoError(t, s.Close())
+
+	s, err = c.Subscribe(context.Background(), &client.Request{Query: `subscription { nope }`})
+	if assert.NoError(t, err) {
+		defer s.Close()
+		err := s.Next(&data)
+		assert.EqualError(t, err, `[graphql: Cannot query field "nope" on type "Subscription". (1:16)]`)
+		assert.IsType(t, errors.MultiError{}, err)
+	}
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Upload is a file sent as the value of a variable of the Upload scalar, or of an item or a field
+// of one. It is read once the request is sent, and closed then when it is an io.Closer.
+type Upload struct {
+	File     io.Reader
+	Filename string
+	// ContentType is the content type of the file, application/octet-stream when empty
+	ContentType string
+}
+
+// upload is an Upload of a request, at the paths of the variables holding it.
+type upload struct {
+	*Upload
+	paths []string
+}
+
+// extractUploads returns a copy of value, found at path in the variables, without its uploads,
+// which are returned along with their paths. Values holding no upload are returned as they are.
+func extractUploads(value interface{}, path string) (interface{}, []*upload) {
+	switch v := value.(type) {
+	case *Upload:
+		return nil, []*upload{{v, []string{path}}}
+	case Upload:
+		return nil, []*upload{{&v, []string{path}}}
+	case map[string]interface{}:
+		// the keys are sorted so that the files are always sent in the same order
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var uploads []*upload
+		var copied map[string]interface{}
+		for _, key := range keys {
+			extracted, found := extractUploads(v[key], path+"."+key)
+			if len(found) == 0 {
+				continue
+			}
+			if copied == nil {
+				copied = make(map[string]interface{}, len(v))
+				for key, item := range v {
+					copied[key] = item
+				}
+			}
+			copied[key] = extracted
+			uploads = append(uploads, found...)
+		}
+		if copied == nil {
+			return value, nil
+		}
+		return copied, uploads
+	}
+
+	// lists of uploads may be of any type, like []*Upload
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return value, nil
+	}
+	var uploads []*upload
+	copied := make([]interface{}, rv.Len())
+	for i := range copied {
+		var found []*upload
+		copied[i], found = extractUploads(rv.Index(i).Interface(), path+"."+strconv.Itoa(i))
+		uploads = append(uploads, found...)
+	}
+	if len(uploads) == 0 {
+		return value, nil
+	}
+	return copied, uploads
+}
+
+// encodeMultipart returns the body of a request following the GraphQL multipart request spec,
+// which the graphql package reads, and its content type. The body is written as it is read, the
+// operations and map first and then the files, so that they aren't held in memory.
+func encodeMultipart(req *Request, uploads []*upload) (io.Reader, string, error) {
+	operations, err := json.Marshal(req)
+	if err != nil {
+		return nil, "", err
+	}
+	// the same upload, at several paths, is only sent once
+	var files []*upload
+	index := make(map[*Upload]*upload)
+	for _, u := range uploads {
+		if file, ok := index[u.Upload]; ok {
+			file.paths = append(file.paths, u.paths...)
+			continue
+		}
+		index[u.Upload] = u
+		files = append(files, u)
+	}
+	fileMap := make(map[string][]string, len(files))
+	for i, file := range files {
+		fileMap[strconv.Itoa(i)] = file.paths
+	}
+	mapping, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r, w := io.Pipe()
+	mw := multipart.NewWriter(w)
+	go func() {
+		w.CloseWithError(writeMultipart(mw, operations, mapping, files))
+	}()
+	return r, mw.FormDataContentType(), nil
+}
+
+func writeMultipart(mw *multipart.Writer, operations, mapping []byte, files []*upload) error {
+	defer func() {
+		for _, file := range files {
+			if closer, ok := file.File.(io.Closer); ok {
+				closer.Close()
+			}
+		}
+	}()
+	if err := mw.WriteField("operations", string(operations)); err != nil {
+		return err
+	}
+	if err := mw.WriteField("map", string(mapping)); err != nil {
+		return err
+	}
+	for i, file := range files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+strconv.Itoa(i)+`"; filename="`+escapeQuotes(file.Filename)+`"`)
+		header.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.File); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes a filename for Content-Disposition, like multipart.Writer.CreateFormFile.
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
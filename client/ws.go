@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/shyptr/graphql/errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// wsMessage is a message of the graphql-ws protocol.
+type wsMessage struct {
+	Type    string          `json:"type"`
+	Id      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscription is an operation executed over a websocket, whose server sends a response
+// for every event until it completes or the subscription is closed.
+type Subscription struct {
+	conn *websocket.Conn
+	id   string
+	ctx  context.Context
+	// stop ends the watch of ctx
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe starts req over a websocket with the graphql-ws protocol, the URL of the client
+// being turned into a ws or wss one. The responses are read with Next, and the subscription
+// is closed with Close or by canceling ctx.
+func (c *Client) Subscribe(ctx context.Context, req *Request) (*Subscription, error) {
+	dialer := *websocket.DefaultDialer
+	if c.Dialer != nil {
+		dialer = *c.Dialer
+	}
+	dialer.Subprotocols = []string{"graphql-ws"}
+	url := c.URL
+	if strings.HasPrefix(url, "http") {
+		url = "ws" + strings.TrimPrefix(url, "http")
+	}
+	conn, _, err := dialer.DialContext(ctx, url, c.Header)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{conn: conn, id: newID(), ctx: ctx, stop: make(chan struct{})}
+	if err := s.start(c.InitPayload, req); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.stop:
+		}
+	}()
+	return s, nil
+}
+
+// start initializes the connection and starts the operation on it.
+func (s *Subscription) start(initPayload map[string]interface{}, req *Request) error {
+	var payload interface{}
+	if initPayload != nil {
+		payload = initPayload
+	}
+	if err := s.send("connection_init", "", payload); err != nil {
+		return err
+	}
+	for {
+		var msg wsMessage
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		switch msg.Type {
+		case "connection_ack":
+			return s.send("start", s.id, req)
+		case "connection_error":
+			return payloadError(msg.Payload)
+		}
+	}
+}
+
+func (s *Subscription) send(typ, id string, payload interface{}) error {
+	msg := wsMessage{Type: typ, Id: id}
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		msg.Payload = raw
+	}
+	return s.conn.WriteJSON(msg)
+}
+
+// Next waits for the next response and decodes its data into data, like Client.Do. It returns
+// io.EOF once the server completes the subscription, and the error of ctx once it is canceled.
+func (s *Subscription) Next(data interface{}) error {
+	for {
+		var msg wsMessage
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			if s.ctx.Err() != nil {
+				return s.ctx.Err()
+			}
+			return err
+		}
+		if msg.Id != "" && msg.Id != s.id {
+			continue
+		}
+		switch msg.Type {
+		case "data":
+			var res Response
+			if err := json.Unmarshal(msg.Payload, &res); err != nil {
+				return err
+			}
+			return res.Decode(data)
+		case "error", "connection_error":
+			return payloadError(msg.Payload)
+		case "complete":
+			return io.EOF
+		}
+	}
+}
+
+// Close stops the subscription and closes its websocket.
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		// connection_terminate isn't sent, as the graphql package ends then the subscriptions
+		// of every connection, closing the websocket is enough to end this one
+		s.send("stop", s.id, nil)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// payloadError returns the error of the payload of an error message, which is either the errors of
+// the operation or, from the graphql package, an object whose error is their message.
+func payloadError(payload json.RawMessage) error {
+	var errs errors.MultiError
+	if err := json.Unmarshal(payload, &errs); err == nil && len(errs) > 0 {
+		return errs
+	}
+	var object struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(payload, &object); err == nil && (object.Error != "" || object.Message != "") {
+		message := object.Message
+		if object.Error != "" {
+			// the graphql package sends the text of its errors, like [graphql: message (1:2)]
+			message = object.Error
+			if strings.HasPrefix(message, "[") && strings.HasSuffix(message, "]") {
+				message = message[1 : len(message)-1]
+			}
+			message = strings.TrimPrefix(message, "graphql: ")
+		}
+		return errors.MultiError{errors.New("%s", message)}
+	}
+	return fmt.Errorf("graphql: unexpected error payload: %s", payload)
+}
+
+// newID returns a random id for an operation, as the server may key them across connections.
+func newID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/shyptr/graphql/federation"
+	"io"
+)
+
+// diff prints the changes from the old to the new schema, and fails when some of them are breaking.
+func diff(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dangerous := flags.Bool("dangerous", false, "fail on dangerous changes too")
+	symbol := flags.String("symbol", "Schema", "the symbol of the schema in Go plugins")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: graphql diff [-dangerous] [-symbol name] old.json|old.so new.json|new.so")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	old, err := loadSchemaJSON(flags.Arg(0), *symbol)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	new, err := loadSchemaJSON(flags.Arg(1), *symbol)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	changes, err := federation.DiffSchemaJSON(old, new)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	if changes.Breaking() || *dangerous && len(changes.Level(federation.Dangerous)) > 0 {
+		return 1
+	}
+	return 0
+}
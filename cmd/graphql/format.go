@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/printer"
+	"io"
+	"io/ioutil"
+)
+
+// format pretty-prints the operations of files, to the standard output or back to the files.
+// Formatting drops comments, so the files holding some are not written back.
+func format(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("format", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the result to the files without comments instead of the standard output")
+	list := flags.Bool("l", false, "list the files whose formatting differs")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: graphql format [-l] [-w] query.graphql...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	code := 0
+	for _, path := range flags.Args() {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		formatted, syntaxErr := formatDocument(string(source))
+		if syntaxErr != nil {
+			printError(stdout, path, syntaxErr)
+			code = 1
+			continue
+		}
+		if *list && formatted != string(source) {
+			fmt.Fprintln(stdout, path)
+		}
+		if *write {
+			if formatted == string(source) {
+				continue
+			}
+			if internal.HasComments(string(source)) {
+				fmt.Fprintf(stderr, "%s: not written, formatting would drop its comments\n", path)
+				code = 1
+				continue
+			}
+			if err := ioutil.WriteFile(path, []byte(formatted), 0644); err != nil {
+				fmt.Fprintln(stderr, err)
+				return 2
+			}
+		} else if !*list {
+			fmt.Fprint(stdout, formatted)
+		}
+	}
+	return code
+}
+
+// formatDocument pretty-prints source, which must only hold operations and fragments.
+func formatDocument(source string) (string, *errors.GraphQLError) {
+	// Parse rejects the type system definitions
+	if _, err := internal.Parse(source); err != nil {
+		return "", err.(*errors.GraphQLError)
+	}
+	doc, err := internal.ParseDocument(source)
+	if err != nil {
+		return "", err
+	}
+	return printer.PrintDocument(doc), nil
+}
@@ -0,0 +1,66 @@
+// Command graphql works with GraphQL schemas and queries from the command line.
+//
+// Usage:
+//
+//	graphql print-schema [-symbol name] schema
+//	graphql validate -schema schema [-symbol name] query.graphql...
+//	graphql diff [-dangerous] [-symbol name] old new
+//	graphql format [-l] [-w] query.graphql...
+//
+// Schemas are given as JSON files holding the result of the introspection query, like those of
+// introspection.ComputeSchemaJSON, or as Go plugins built with -buildmode=plugin, whose Schema
+// symbol, or the one named by -symbol, is a *schemabuilder.Schema, a function returning it,
+// or a built schema:
+//
+//	package main
+//
+//	var Schema = schemabuilder.NewSchema()
+//
+//	func init() {
+//		Schema.Query().FieldFunc("hello", func() string { return "world" })
+//	}
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: graphql <command> [arguments]
+
+commands:
+  print-schema  print a schema in the schema definition language
+  validate      check queries against a schema
+  diff          compare two schemas and report their breaking changes
+  format        pretty-print queries
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command of args, and returns the exit code: 0 on success, 1 when the schemas
+// or queries have problems, and 2 when the command can't be run.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch command, args := args[0], args[1:]; command {
+	case "print-schema":
+		return printSchema(args, stdout, stderr)
+	case "validate":
+		return validate(args, stdout, stderr)
+	case "diff":
+		return diff(args, stdout, stderr)
+	case "format":
+		return format(args, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "graphql: unknown command %q\n%s", command, usage)
+		return 2
+	}
+}
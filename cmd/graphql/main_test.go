@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const testSDL = `"""A pet."""
+type Pet {
+  age: Int
+  name: String!
+}
+
+type Query {
+  owner: String
+  pet(name: String!): Pet
+}
+`
+
+// runCommand runs the command line args, and returns its exit code and outputs.
+func runCommand(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+// writeFiles writes the files of contents, by name, into a temporary directory, and returns their paths.
+func writeFiles(t *testing.T, contents map[string]string) map[string]string {
+	dir, err := ioutil.TempDir("", "graphql-cmd-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	paths := make(map[string]string)
+	for name, content := range contents {
+		paths[name] = filepath.Join(dir, name)
+		if err := ioutil.WriteFile(paths[name], []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestRun(t *testing.T) {
+	code, stdout, stderr := runCommand()
+	assert.Equal(t, 2, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, usage, stderr)
+
+	code, stdout, stderr = runCommand("help")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, usage, stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCommand("lint")
+	assert.Equal(t, 2, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, `graphql: unknown command "lint"`)
+
+	code, _, stderr = runCommand("print-schema", "-unknown")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: graphql print-schema")
+}
+
+func TestPrintSchema(t *testing.T) {
+	code, stdout, stderr := runCommand("print-schema", "testdata/schema.json")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, testSDL, stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCommand("print-schema", "testdata/missing.json")
+	assert.Equal(t, 2, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "testdata/missing.json: ")
+
+	code, _, stderr = runCommand("print-schema")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: graphql print-schema")
+}
+
+func TestValidate(t *testing.T) {
+	files := writeFiles(t, map[string]string{
+		"valid.graphql":   "query ($name: String!) { pet(name: $name) { name age } __schema { types { name } } }",
+		"invalid.graphql": "{ pet { name } owner { name } }",
+		"syntax.graphql":  "{ pet(",
+	})
+
+	code, stdout, stderr := runCommand("validate", "-schema", "testdata/schema.json", files["valid.graphql"])
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCommand("validate", "-schema", "testdata/schema.json",
+		files["valid.graphql"], files["invalid.graphql"], files["syntax.graphql"])
+	assert.Equal(t, 1, code)
+	assert.Equal(t, files["invalid.graphql"]+`:1:7: Field "pet" argument "name" of type "String!" is required, but it was not provided.`+"\n"+
+		files["invalid.graphql"]+`:1:22: Field "owner" must not have a selection since type "String" has no subfields.`+"\n"+
+		files["syntax.graphql"]+`:1:7: Syntax Error: Expected Ident, found "".`+"\n", stdout)
+	assert.Empty(t, stderr)
+
+	code, _, stderr = runCommand("validate", files["valid.graphql"])
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: graphql validate")
+
+	code, _, stderr = runCommand("validate", "-schema", "testdata/schema.json", "missing.graphql")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "missing.graphql")
+}
+
+func TestDiff(t *testing.T) {
+	code, stdout, stderr := runCommand("diff", "testdata/schema.json", "testdata/schema.json")
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCommand("diff", "testdata/schema.json", "testdata/new.json")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "breaking: Query.owner: field removed\nsafe: Query.pets: field added\n", stdout)
+	assert.Empty(t, stderr)
+
+	code, _, stderr = runCommand("diff", "testdata/schema.json")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: graphql diff")
+
+	code, _, stderr = runCommand("diff", "testdata/schema.json", "testdata/missing.json")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "missing.json")
+}
+
+func TestFormat(t *testing.T) {
+	const formatted = "{\n  pet(name: \"# not a comment\") {\n    name\n  }\n}\n"
+	files := writeFiles(t, map[string]string{
+		"messy.graphql":     `{ pet(name: "# not a comment") { name } }`,
+		"commented.graphql": "# the pet\n{ pet(name: \"a\") { name } }\n",
+		"formatted.graphql": formatted,
+		"syntax.graphql":    "{ pet(",
+		"schema.graphql":    "type Query { pet: String }",
+	})
+
+	code, stdout, stderr := runCommand("format", files["messy.graphql"])
+	assert.Equal(t, 0, code)
+	assert.Equal(t, formatted, stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCommand("format", "-l", files["messy.graphql"], files["formatted.graphql"], files["commented.graphql"])
+	assert.Equal(t, 0, code)
+	assert.Equal(t, files["messy.graphql"]+"\n"+files["commented.graphql"]+"\n", stdout)
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCommand("format", "-w", files["messy.graphql"], files["commented.graphql"])
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, files["commented.graphql"]+": not written, formatting would drop its comments\n", stderr)
+	content, _ := ioutil.ReadFile(files["messy.graphql"])
+	assert.Equal(t, formatted, string(content))
+	content, _ = ioutil.ReadFile(files["commented.graphql"])
+	assert.Equal(t, "# the pet\n{ pet(name: \"a\") { name } }\n", string(content))
+
+	code, stdout, _ = runCommand("format", files["syntax.graphql"], files["schema.graphql"])
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, files["syntax.graphql"]+":1:7: Syntax Error")
+	assert.Contains(t, stdout, files["schema.graphql"]+":1:1: ")
+
+	code, _, stderr = runCommand("format")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: graphql format")
+}
+
+const testPlugin = `package main
+
+import "github.com/shyptr/graphql/schemabuilder"
+
+type Pet struct {
+	Name string ` + "`graphql:\"name\"`" + `
+}
+
+var Schema = schemabuilder.NewSchema()
+
+var Count = 1
+
+func New() *schemabuilder.Schema {
+	return Schema
+}
+
+func init() {
+	Schema.Object("Pet", Pet{}, "")
+	Schema.Query().FieldFunc("pet", func() *Pet { return nil }, "")
+}
+`
+
+func TestLoadPlugin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building a plugin is slow")
+	}
+	files := writeFiles(t, map[string]string{"schema.go": testPlugin})
+	dir := filepath.Dir(files["schema.go"])
+	plugin := filepath.Join(dir, "schema.so")
+	build := exec.Command("go", "build", "-buildmode=plugin", "-o", plugin, files["schema.go"])
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Skipf("plugins can't be built here: %v\n%s", err, out)
+	}
+
+	code, stdout, stderr := runCommand("print-schema", plugin)
+	if code == 2 && bytes.Contains([]byte(stderr), []byte("different version of package")) {
+		t.Skip("the plugin was built with other packages than the test")
+	}
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "type Pet {\n  name: String!\n}\n\ntype Query {\n  pet: Pet\n}\n", stdout)
+
+	code, stdout, stderr = runCommand("print-schema", "-symbol", "New", plugin)
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "type Query {\n  pet: Pet\n}\n")
+
+	code, _, stderr = runCommand("print-schema", "-symbol", "Count", plugin)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Count is a *int, not a schema")
+
+	code, _, stderr = runCommand("print-schema", "-symbol", "Missing", plugin)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Missing")
+}
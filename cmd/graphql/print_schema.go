@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/shyptr/graphql/printer"
+	"io"
+)
+
+// printSchema prints a schema in the schema definition language.
+func printSchema(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("print-schema", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	symbol := flags.String("symbol", "Schema", "the symbol of the schema in Go plugins")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: graphql print-schema [-symbol name] schema.json|schema.so")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	schema, err := loadSchema(flags.Arg(0), *symbol)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	fmt.Fprint(stdout, printer.PrintSchema(schema))
+	return 0
+}
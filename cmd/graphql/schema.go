@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shyptr/graphql/federation"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+	"io/ioutil"
+	"path/filepath"
+	"plugin"
+)
+
+// loadSchema loads the schema of path, which is either a Go plugin, built with -buildmode=plugin,
+// exporting the schema as symbol, or a JSON file holding the result of the introspection query.
+// The plugin's symbol may be a *schemabuilder.Schema, a function returning it, or a built schema.
+// The introspection fields are added to the schema, so that queries can ask for them.
+func loadSchema(path, symbol string) (*internal.Schema, error) {
+	var schema *internal.Schema
+	var err error
+	if filepath.Ext(path) == ".so" {
+		schema, err = loadPlugin(path, symbol)
+	} else {
+		var schemaJSON []byte
+		if schemaJSON, err = ioutil.ReadFile(path); err == nil {
+			schema, err = federation.ParseSchema(schemaJSON)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if query, ok := schema.Query.(*internal.Object); ok && query.Fields["__schema"] == nil {
+		introspection.AddIntrospectionToSchema(schema)
+	}
+	return schema, nil
+}
+
+func loadPlugin(path, symbol string) (*internal.Schema, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(symbol)
+	if err != nil {
+		return nil, err
+	}
+	switch sym := sym.(type) {
+	case func() *schemabuilder.Schema:
+		return sym().Build()
+	case **schemabuilder.Schema:
+		if *sym != nil {
+			return (*sym).Build()
+		}
+	case **internal.Schema:
+		if *sym != nil {
+			return *sym, nil
+		}
+	default:
+		return nil, fmt.Errorf("%s is a %T, not a schema", symbol, sym)
+	}
+	return nil, fmt.Errorf("%s is nil", symbol)
+}
+
+// loadSchemaJSON returns the result of the introspection query on the schema of path,
+// which is read as it is from JSON files.
+func loadSchemaJSON(path, symbol string) ([]byte, error) {
+	if filepath.Ext(path) != ".so" {
+		return ioutil.ReadFile(path)
+	}
+	schema, err := loadSchema(path, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return introspection.ComputeSchemaJSON(schema)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/validation"
+	"io"
+	"io/ioutil"
+)
+
+// validate checks the operations of files against a schema, and prints their errors.
+func validate(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	schemaPath := flags.String("schema", "", "the schema, as a JSON introspection result or a Go plugin")
+	symbol := flags.String("symbol", "Schema", "the symbol of the schema in Go plugins")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: graphql validate -schema schema.json|schema.so [-symbol name] query.graphql...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaPath == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	schema, err := loadSchema(*schemaPath, *symbol)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	code := 0
+	for _, path := range flags.Args() {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		var errs errors.MultiError
+		if doc, err := internal.Parse(string(source)); err != nil {
+			errs = errors.MultiError{err.(*errors.GraphQLError)}
+		} else {
+			errs = validation.Validate(schema, doc)
+		}
+		for _, err := range errs {
+			printError(stdout, path, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// printError prints err at its locations in path to w, like compilers do.
+func printError(w io.Writer, path string, err *errors.GraphQLError) {
+	if len(err.Locations) == 0 {
+		fmt.Fprintf(w, "%s: %s\n", path, err.Message)
+		return
+	}
+	for _, loc := range err.Locations {
+		fmt.Fprintf(w, "%s:%d:%d: %s\n", path, loc.Line, loc.Column, err.Message)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,8 +22,19 @@ type Context struct {
 	Request *http.Request
 	Writer  *Resp
 	// keys is a key/value pair exclusively for the Context of each request.
-	keys                  map[interface{}]interface{}
+	keys map[interface{}]interface{}
+	// mu guards keys, fields may resolve concurrently and share one Context.
+	mu *sync.RWMutex
+	// parent is the context of the request, it carries its cancellation and deadline.
+	parent                context.Context
 	MaxDepth              int
+	MaxFields             int
+	MaxAliases            int
+	MaxDirectives         int
+	MaxBatchSize          int
+	MaxUploadSize         int64
+	MaxUploads            int
+	Timeout               time.Duration
 	Logger                *log.Logger
 	useStringDescriptions bool
 	HandlersChain         []HandlerFunc
@@ -36,6 +49,7 @@ var Ctx = &Context{
 	Writer:                nil,
 	keys:                  nil,
 	MaxDepth:              50,
+	MaxBatchSize:          10,
 	Logger:                log.New(os.Stderr, "", 0),
 	useStringDescriptions: false,
 	HandlersChain:         []HandlerFunc{},
@@ -43,30 +57,70 @@ var Ctx = &Context{
 	index:                 -1,
 }
 
+// forRequest returns the Context of a request served with the settings of c, running
+// its middleware then handler. The Context is the request's own, c is never modified.
+func (c *Context) forRequest(w http.ResponseWriter, r *http.Request, handler HandlerFunc) *Context {
+	ctx := *c
+	ctx.Writer, ctx.Request, ctx.parent = &Resp{ResponseWriter: w}, r, r.Context()
+	ctx.keys = make(map[interface{}]interface{})
+	ctx.mu = new(sync.RWMutex)
+	// the chain is copied, appending to the shared one could write to its spare capacity
+	ctx.HandlersChain = make([]HandlerFunc, 0, len(c.HandlersChain)+1)
+	ctx.HandlersChain = append(append(ctx.HandlersChain, c.HandlersChain...), handler)
+	ctx.Error = nil
+	ctx.index = -1
+	return &ctx
+}
+
 func GetContext(ctx context.Context) *Context {
 	return ctx.(*Context)
 }
 
+// Deadline, Done and Err are those of the context of the request, with the timeout of the operation applied.
+// The errors of the operation are in Context.Error.
 func (c *Context) Deadline() (deadline time.Time, ok bool) {
-	return
+	if c.parent == nil {
+		return
+	}
+	return c.parent.Deadline()
 }
 
 func (c *Context) Done() <-chan struct{} {
-	return nil
+	if c.parent == nil {
+		return nil
+	}
+	return c.parent.Done()
 }
 
 func (c *Context) Err() error {
-	if len(c.Error) == 0 {
+	if c.parent == nil {
 		return nil
 	}
-	return c.Error
+	return c.parent.Err()
 }
 
+// Value returns the value set for key, or the value of the context of the request.
 func (c *Context) Value(key interface{}) interface{} {
-	return c.keys[key]
+	if c.mu != nil {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+	}
+	if value, ok := c.keys[key]; ok {
+		return value
+	}
+	if c.parent == nil {
+		return nil
+	}
+	return c.parent.Value(key)
 }
 
 func (c *Context) Set(key, value interface{}) {
+	if c.mu == nil {
+		c.keys[key] = value
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.keys[key] = value
 }
 
@@ -78,11 +132,56 @@ func UseStringDescriptions() {
 	Ctx.useStringDescriptions = true
 }
 
-// MaxDepth specifies the maximum field nesting depth in a query. The default is 0 which disables max depth checking.
+// MaxDepth specifies the maximum field nesting depth in a query. The default is 50, 0 disables max depth checking.
 func MaxDepth(n int) {
 	Ctx.MaxDepth = n
 }
 
+// MaxFields specifies the maximum number of fields in a query, counting every fragment spread. The default is 0 which disables the check.
+func MaxFields(n int) {
+	Ctx.MaxFields = n
+}
+
+// MaxAliases specifies the maximum number of aliases in a selection set. The default is 0 which disables the check.
+func MaxAliases(n int) {
+	Ctx.MaxAliases = n
+}
+
+// MaxDirectives specifies the maximum number of directives on a field. The default is 0 which disables the check.
+func MaxDirectives(n int) {
+	Ctx.MaxDirectives = n
+}
+
+// MaxBatchSize specifies the maximum number of operations sent together in one request. The default is 10, 0 disables the check.
+func MaxBatchSize(n int) {
+	Ctx.MaxBatchSize = n
+}
+
+// MaxUploadSize specifies the maximum size in bytes of a file uploaded with a request. The default is 0 which disables the check.
+func MaxUploadSize(n int64) {
+	Ctx.MaxUploadSize = n
+}
+
+// MaxUploads specifies the maximum number of files uploaded with a request. The default is 0 which disables the check.
+func MaxUploads(n int) {
+	Ctx.MaxUploads = n
+}
+
+// Timeout specifies the maximum duration of an operation. The fields still unresolved when it is reached
+// are abandoned with an error, and the context given to resolvers is cancelled. The default is 0 which disables the timeout.
+func Timeout(d time.Duration) {
+	Ctx.Timeout = d
+}
+
+func (c *Context) limits() execution.Limits {
+	return execution.Limits{
+		MaxDepth:      c.MaxDepth,
+		MaxFields:     c.MaxFields,
+		MaxAliases:    c.MaxAliases,
+		MaxDirectives: c.MaxDirectives,
+	}
+}
+
 // Logger is used to log panics during query execution. It defaults to exec.DefaultLogger.
 func SetLogger(logger *log.Logger) {
 	Ctx.Logger = logger
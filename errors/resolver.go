@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+)
+
+// ExtendedError is implemented by the errors of resolvers which carry extensions,
+// they are copied into the extensions of the GraphQLError reporting them.
+type ExtendedError interface {
+	error
+	Extensions() map[string]interface{}
+}
+
+// Extensions returns the extensions of the first ExtendedError in the chain of err, or nil.
+func Extensions(err error) map[string]interface{} {
+	for err != nil {
+		if extended, ok := err.(ExtendedError); ok {
+			return extended.Extensions()
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil
+		}
+		err = unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// Error is an error resolvers can return to give clients a code along with the message.
+type Error struct {
+	Message string
+	// Code is set as the code extension
+	Code string
+	// Data is reported in the extensions along with the code
+	Data map[string]interface{}
+	// Err is the cause of the error, it is not reported to clients
+	Err error
+}
+
+// Errorf returns an Error with code and a formatted message.
+func Errorf(code string, format string, arg ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, arg...), Code: code}
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// Extensions implements ExtendedError.
+func (err *Error) Extensions() map[string]interface{} {
+	extensions := make(map[string]interface{}, len(err.Data)+1)
+	for k, v := range err.Data {
+		extensions[k] = v
+	}
+	if err.Code != "" {
+		extensions["code"] = err.Code
+	}
+	return extensions
+}
+
+// PanicError is the error of a resolver which panicked. Clients are only given a
+// stable message and the ID, which correlates the response with the logged Value and Stack.
+type PanicError struct {
+	ID    string
+	Value interface{}
+	Stack []byte
+}
+
+// Stack returns the stack of the calling goroutine, up to 64KB, for the PanicErrors of the
+// resolvers and loaders recovering panics.
+func Stack() []byte {
+	const size = 64 << 10
+	buf := make([]byte, size)
+	return buf[:runtime.Stack(buf, false)]
+}
+
+// NewPanicError returns a PanicError with a new random ID.
+func NewPanicError(value interface{}, stack []byte) *PanicError {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &PanicError{ID: hex.EncodeToString(id), Value: value, Stack: stack}
+}
+
+func (err *PanicError) Error() string {
+	return "internal error"
+}
+
+// Extensions implements ExtendedError.
+func (err *PanicError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "INTERNAL_SERVER_ERROR", "correlationId": err.ID}
+}
+
+// Detail returns the panic with its stack, for logging.
+func (err *PanicError) Detail() string {
+	return fmt.Sprintf("panic %s: %v\n%s", err.ID, err.Value, err.Stack)
+}
@@ -0,0 +1,132 @@
+package execution
+
+import (
+	"context"
+	"github.com/shyptr/graphql/internal"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// batcher collects the sources of the batch resolvers of one execution. A batch resolver
+// is only called once no goroutine of the execution can progress without it, with the
+// sources of every parent waiting for it by then: the parents at the same depth, across
+// lists and abstract types, are resolved with a single call.
+type batcher struct {
+	ctx context.Context
+	mu  sync.Mutex
+	// active counts the goroutines of the execution which are running
+	active int
+	// waiting counts the goroutines waiting for the pending calls
+	waiting int
+	pending []*batchCall
+}
+
+// batchCall is a call of a batch resolver, for the sources of every load of the same field with the same arguments.
+type batchCall struct {
+	field   *internal.Field
+	args    interface{}
+	sources []interface{}
+	done    chan struct{}
+	results []interface{}
+	err     error
+	// start and end time the call of the batch resolver
+	start, end time.Time
+}
+
+// batchLoad is the part of a call resolving the sources of one load.
+type batchLoad struct {
+	call      *batchCall
+	offset, n int
+}
+
+// newBatcher returns the batcher of an execution run by the calling goroutine.
+func newBatcher(ctx context.Context) *batcher {
+	return &batcher{ctx: ctx, active: 1}
+}
+
+// load adds sources to the call of field with args, which is made by the next wait.
+func (b *batcher) load(field *internal.Field, args interface{}, sources []interface{}) *batchLoad {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var call *batchCall
+	for _, pending := range b.pending {
+		if pending.field == field && reflect.DeepEqual(pending.args, args) {
+			call = pending
+			break
+		}
+	}
+	if call == nil {
+		call = &batchCall{field: field, args: args, done: make(chan struct{})}
+		b.pending = append(b.pending, call)
+	}
+	load := &batchLoad{call: call, offset: len(call.sources), n: len(sources)}
+	call.sources = append(call.sources, sources...)
+	return load
+}
+
+// wait waits for the calls of loads, which the loads of the calling goroutine were added to.
+func (b *batcher) wait(loads ...*batchLoad) {
+	b.mu.Lock()
+	b.waiting++
+	b.active--
+	calls := b.ready()
+	b.mu.Unlock()
+	b.call(calls)
+	for _, load := range loads {
+		<-load.call.done
+	}
+}
+
+// start and stop count a goroutine of the execution starting or stopping to run, either
+// because it is done or because it waits for goroutines which may wait for batches.
+func (b *batcher) start() {
+	b.mu.Lock()
+	b.active++
+	b.mu.Unlock()
+}
+
+func (b *batcher) stop() {
+	b.mu.Lock()
+	b.active--
+	calls := b.ready()
+	b.mu.Unlock()
+	b.call(calls)
+}
+
+// ready returns the pending calls once no goroutine is running.
+func (b *batcher) ready() []*batchCall {
+	if b.active > 0 || len(b.pending) == 0 {
+		return nil
+	}
+	calls := b.pending
+	b.pending = nil
+	return calls
+}
+
+// call makes calls, then wakes up the goroutines waiting for them. Nothing else runs meanwhile.
+func (b *batcher) call(calls []*batchCall) {
+	if len(calls) == 0 {
+		return
+	}
+	for _, call := range calls {
+		call.start = time.Now()
+		call.results, call.err = safeExecuteBatchResolver(b.ctx, call.field, call.sources, call.args)
+		call.end = time.Now()
+	}
+	b.mu.Lock()
+	b.active += b.waiting
+	b.waiting = 0
+	b.mu.Unlock()
+	for _, call := range calls {
+		close(call.done)
+	}
+}
+
+// results returns the results of the sources of l.
+func (l *batchLoad) results() ([]interface{}, error) {
+	if l.call.err != nil {
+		return nil, l.call.err
+	}
+	return l.call.results[l.offset : l.offset+l.n], nil
+}
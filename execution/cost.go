@@ -0,0 +1,108 @@
+package execution
+
+import (
+	"github.com/shyptr/graphql/internal"
+)
+
+// Cost computes the cost of executing selectionSet against typ, without running any resolver.
+//
+// Every field costs its internal.Field.Cost when it declares one. Otherwise fields
+// returning objects, interfaces or unions, or lists of them, cost 1, and leaf fields are free.
+// The cost of the sub selections of a field is multiplied by its first or last
+// argument, so that paginated connections are charged for every element they may return.
+// Fragments on abstract types are all counted, so the cost is an upper bound.
+// Costs too large to be represented are capped to the largest int, which exceeds every budget.
+func Cost(schema *internal.Schema, typ internal.Type, selectionSet *internal.SelectionSet) int {
+	return selectionSetCost(schema, typ, selectionSet, map[*internal.SelectionSet]bool{})
+}
+
+func selectionSetCost(schema *internal.Schema, typ internal.Type, selectionSet *internal.SelectionSet,
+	visiting map[*internal.SelectionSet]bool) int {
+	if selectionSet == nil || visiting[selectionSet] {
+		return 0
+	}
+	visiting[selectionSet] = true
+	defer delete(visiting, selectionSet)
+
+	named, _ := unwrapType(typ)
+	fieldMap := fields(named)
+
+	var cost int
+	for _, selection := range selectionSet.Selections {
+		field := fieldMap[selection.Name]
+		if field == nil {
+			continue
+		}
+		cost = addCost(cost, fieldCost(field))
+		if selection.SelectionSet != nil {
+			cost = addCost(cost, mulCost(multiplier(selection.Args), selectionSetCost(schema, field.Type, selection.SelectionSet, visiting)))
+		}
+	}
+	for _, fragment := range selectionSet.Fragments {
+		on := typ
+		if t, ok := schema.TypeMap[fragment.Fragment.On]; ok {
+			on = t
+		}
+		cost = addCost(cost, selectionSetCost(schema, on, fragment.Fragment.SelectionSet, visiting))
+	}
+	return cost
+}
+
+// maxCost is the cost of the operations whose cost overflows an int.
+const maxCost = int(^uint(0) >> 1)
+
+// addCost and mulCost add and multiply costs, which are never negative, saturating at maxCost.
+func addCost(a, b int) int {
+	if a > maxCost-b {
+		return maxCost
+	}
+	return a + b
+}
+
+func mulCost(a, b int) int {
+	if a != 0 && b > maxCost/a {
+		return maxCost
+	}
+	return a * b
+}
+
+func fieldCost(field *internal.Field) int {
+	if field.Cost != nil {
+		return *field.Cost
+	}
+	if hasSubfields(field.Type) {
+		return 1
+	}
+	return 0
+}
+
+// multiplier returns the page size requested by the first or last argument, or 1.
+func multiplier(args interface{}) int {
+	argMap, ok := args.(map[string]interface{})
+	if !ok {
+		return 1
+	}
+	m := 1
+	for _, name := range []string{"first", "last"} {
+		var n int
+		switch v := argMap[name].(type) {
+		case float64:
+			// a page size an int can't hold is over every budget
+			if v >= float64(maxCost) {
+				return maxCost
+			}
+			n = int(v)
+		case int:
+			n = v
+		case int64:
+			if v >= int64(maxCost) {
+				return maxCost
+			}
+			n = int(v)
+		}
+		if n > m {
+			m = n
+		}
+	}
+	return m
+}
@@ -0,0 +1,54 @@
+package execution_test
+
+import (
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCost(t *testing.T) {
+	type Item struct {
+		ID   int    `graphql:"id"`
+		Name string `graphql:"name"`
+	}
+	build := schemabuilder.NewSchema()
+	item := build.Object("Item", Item{})
+	item.FieldFunc("price", func() float64 { return 0 }, "", schemabuilder.Cost(5))
+	item.FieldFunc("related", func() []*Item { return nil }, "")
+	item.FieldFunc("children", func() []*Item { return nil }, "", schemabuilder.RelayConnection)
+	schemabuilder.RelayKey(Item{}, "id")
+	build.Query().FieldFunc("item", func() *Item { return nil }, "")
+	build.Query().FieldFunc("items", func() []*Item { return nil }, "", schemabuilder.RelayConnection)
+	build.Query().FieldFunc("search", func() []*Item { return nil }, "", schemabuilder.Cost(10))
+	schema := build.MustBuild()
+
+	cost := func(query string) int {
+		doc, err := internal.Parse(query)
+		assert.NoError(t, err)
+		_, selectionSet, err := execution.ApplySelectionSet(schema, doc, "", map[string]interface{}{"n": float64(20)})
+		assert.NoError(t, err)
+		return execution.Cost(schema, schema.Query, selectionSet)
+	}
+
+	assert.Equal(t, 0, cost("{ __typename }"))
+	assert.Equal(t, 1, cost("{ item { id name } }"))
+	assert.Equal(t, 7, cost("{ item { price related { id } } }"))
+	assert.Equal(t, 10, cost("{ search { id } }"))
+	// connection, then edges, node and related for every edge
+	assert.Equal(t, 1+3*(1+1+1), cost("{ items(first: 3) { edges { node { id related { id } } } } }"))
+	assert.Equal(t, 1+20*(1+1+5), cost("query($n: Int) { items(last: $n) { edges { node { price } } } }"))
+	assert.Equal(t, 2*(1+5), cost("{ item { price } ...G } fragment G on Query { other: item { price } }"))
+
+	// costs saturate instead of overflowing, so that they can't get under a budget
+	const maxInt = int(^uint(0) >> 1)
+	assert.Equal(t, maxInt, cost(`{ items(first: 2147483647) { edges { node {
+		children(first: 2147483647) { edges { node { children(first: 2147483647) { edges { node { id } } } } } }
+	} } } }`))
+
+	build = schemabuilder.NewSchema()
+	build.Query().FieldFunc("item", func() *Item { return nil }, "", schemabuilder.Cost(-1))
+	_, err := build.Build()
+	assert.Error(t, err)
+}
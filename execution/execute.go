@@ -7,43 +7,144 @@ import (
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/internal"
 	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
 	"reflect"
-	"runtime"
 	"strings"
+	"sync"
+	"time"
 )
 
+// Executor resolves a parsed selection set against a schema.
+//
+// The selections of queries and subscriptions are resolved concurrently,
+// while the root selections of a mutation are resolved one after another
+// in the order given by the query, as required by the spec.
 type Executor struct {
-	iterate bool
+	// MaxConcurrency bounds the number of goroutines resolving the fields of
+	// a single operation. Zero means no bound, one resolves every field serially.
+	MaxConcurrency int
 }
 
 type exeContext struct {
 	context.Context
-	errs errors.MultiError
+	*exeState
 	path []interface{}
+	// loc is the location of the field being executed
+	loc errors.Location
+	// serial is only set on the root of a mutation
+	serial bool
+	// stream is set while executing a list field marked with @stream
+	stream *internal.Stream
+}
+
+// exeState is shared by every branch of one execution.
+type exeState struct {
+	mu      sync.Mutex
+	errs    errors.MultiError
+	workers chan struct{}
+	batches *batcher
+	// tracing is nil unless the execution is traced
+	tracing *Tracing
+	// incremental is nil unless @defer and @stream are delivered in subsequent payloads
+	incremental *incremental
+	// pending are the subsequent payloads found by this execution, they run once it is delivered
+	pending []pendingPayload
 }
 
 func (e *exeContext) addErr(location errors.Location, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.errs = append(e.errs, &errors.GraphQLError{
 		Message:       err.Error(),
 		ResolverError: err,
 		Locations:     []errors.Location{location},
 		Path:          e.path,
+		Extensions:    errors.Extensions(err),
 	})
 }
 
-func (e *exeContext) updatePath(add bool, path ...interface{}) {
-	if add {
-		e.path = append(e.path, path...)
-	} else if len(e.path) > 0 {
-		e.path = e.path[:len(e.path)-1]
+// withPath returns a child context for the given path segments. The path is
+// copied so that sibling branches running concurrently never share it.
+func (e *exeContext) withPath(path ...interface{}) *exeContext {
+	p := make([]interface{}, len(e.path), len(e.path)+len(path))
+	copy(p, e.path)
+	return &exeContext{
+		Context:  e.Context,
+		exeState: e.exeState,
+		path:     append(p, path...),
+		loc:      e.loc,
+	}
+}
+
+// withField returns a child context for executing selection.
+func (e *exeContext) withField(selection *internal.Selection) *exeContext {
+	ctx := e.withPath(selection.Alias)
+	ctx.loc = selection.Loc
+	if e.incremental != nil {
+		ctx.stream = selection.Stream
+	}
+	return ctx
+}
+
+// errNullPropagated is returned in place of a value once a field error has been
+// reported, so that the parents only turn themselves into null, without reporting it again.
+var errNullPropagated = errors.New("null propagated to the parent")
+
+// nullify reports err at the current path, unless it was reported already, and
+// returns errNullPropagated when a value of type typ can not be null.
+// Otherwise the value becomes null and the error is handled.
+func (e *exeContext) nullify(typ internal.Type, err error) error {
+	if err != errNullPropagated {
+		e.addErr(e.loc, err)
+	}
+	if e.incremental != nil {
+		e.dropPending(e.path)
 	}
-	return
+	if _, ok := typ.(*internal.NonNull); ok {
+		return errNullPropagated
+	}
+	return nil
+}
+
+// fork runs fn on a new goroutine when a worker is free, and on the calling
+// goroutine otherwise, so the limit is never exceeded and nested forks can't deadlock.
+func (e *exeContext) fork(wg *sync.WaitGroup, fn func()) {
+	if e.serial {
+		fn()
+		return
+	}
+	if e.workers != nil {
+		select {
+		case e.workers <- struct{}{}:
+		default:
+			fn()
+			return
+		}
+	}
+	wg.Add(1)
+	e.batches.start()
+	go func() {
+		defer wg.Done()
+		defer e.batches.stop()
+		if e.workers != nil {
+			defer func() { <-e.workers }()
+		}
+		fn()
+	}()
+}
+
+// wait waits for the goroutines forked with wg, which may wait for batch resolvers meanwhile.
+func (e *exeContext) wait(wg *sync.WaitGroup) {
+	e.batches.stop()
+	wg.Wait()
+	e.batches.start()
 }
 
 type Params struct {
 	Query         string                 `json:"query"`
 	OperationName string                 `json:"operationName"`
 	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
 	Context       context.Context        `json:"context"`
 }
 
@@ -53,44 +154,70 @@ func Do(schema *internal.Schema, param Params) (interface{}, errors.MultiError)
 	if err != nil {
 		return nil, []*errors.GraphQLError{err.(*errors.GraphQLError)}
 	}
+	if errs := validation.Validate(schema, doc); len(errs) > 0 {
+		return nil, errs
+	}
 
 	operationType, selectionSet, err := ApplySelectionSet(schema, doc, param.OperationName, param.Variables)
 	if err != nil {
 		return nil, []*errors.GraphQLError{err.(*errors.GraphQLError)}
 	}
 	root := schema.Query
-	if operationType == ast.Mutation {
+	switch operationType {
+	case ast.Mutation:
 		root = schema.Mutation
+	case ast.Subscription:
+		root = schema.Subscription
 	}
 	executor := &Executor{}
 	ctx := param.Context
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return executor.Execute(ctx, root, nil, selectionSet)
+	return executor.ExecuteOperation(ctx, operationType, root, nil, selectionSet)
 }
 
+// Execute resolves selectionSet against typ with every field resolved concurrently.
 func (e *Executor) Execute(ctx context.Context, typ internal.Type, source interface{},
 	selectionSet *internal.SelectionSet) (interface{}, errors.MultiError) {
-	exeCtx := &exeContext{Context: ctx}
+	return e.ExecuteOperation(ctx, ast.Query, typ, source, selectionSet)
+}
+
+// ExecuteOperation is like Execute, but resolves the root fields of a mutation serially.
+func (e *Executor) ExecuteOperation(ctx context.Context, operation ast.OperationType, typ internal.Type, source interface{},
+	selectionSet *internal.SelectionSet) (interface{}, errors.MultiError) {
+	return e.run(e.newContext(ctx, operation), typ, source, selectionSet)
+}
+
+func (e *Executor) newContext(ctx context.Context, operation ast.OperationType) *exeContext {
+	exeCtx := &exeContext{
+		Context:  schemabuilder.WithLoaders(ctx),
+		exeState: &exeState{},
+		serial:   operation == ast.Mutation,
+	}
+	exeCtx.batches = newBatcher(exeCtx.Context)
+	exeCtx.tracing, _ = ctx.Value(tracingKey{}).(*Tracing)
+	if e.MaxConcurrency > 0 {
+		exeCtx.workers = make(chan struct{}, e.MaxConcurrency-1)
+	}
+	return exeCtx
+}
+
+func (e *Executor) run(exeCtx *exeContext, typ internal.Type, source interface{},
+	selectionSet *internal.SelectionSet) (interface{}, errors.MultiError) {
 	response, err := e.execute(exeCtx, typ, source, selectionSet)
 	if err != nil {
-		exeCtx.addErr(selectionSet.Loc, err)
+		// a non-null field failed and nulled every parent up to the root
+		if err != errNullPropagated {
+			exeCtx.addErr(selectionSet.Loc, err)
+		}
+		return nil, exeCtx.errs
 	}
 	return response, exeCtx.errs
 }
 
 func (e *Executor) execute(ctx *exeContext, typ internal.Type, source interface{},
 	selectionSet *internal.SelectionSet) (interface{}, error) {
-	if err := ctx.Err(); err != nil {
-		if err, ok := err.(errors.MultiError); ok {
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
-	}
 	switch typ := typ.(type) {
 	case *internal.Scalar:
 		if typ.Serialize != nil {
@@ -144,7 +271,7 @@ func (e *Executor) executeUnion(ctx *exeContext, typ *internal.Union, source int
 		return nil, nil
 	}
 
-	fields := make(map[string]interface{})
+	fields := NewOrderedMap(len(selectionSet.Selections))
 
 	var possibleTypes []string
 	for typString, object := range typ.Types {
@@ -158,51 +285,44 @@ func (e *Executor) executeUnion(ctx *exeContext, typ *internal.Union, source int
 		}
 		possibleTypes = append(possibleTypes, object.String())
 		for _, selection := range selectionSet.Selections {
-			func() {
-				ctx.updatePath(true, selection.Name)
-				defer func() {
-					ctx.updatePath(false)
-				}()
-				if selection.Name == "__typename" {
-					fields[selection.Alias] = object.Name
-					return
-				}
-				field := object.Fields[selection.Name]
-				if field != nil {
-					resolved, err := e.resolveAndExecute(ctx, field, inner.Interface(), selection)
-					if err != nil {
-						ctx.addErr(selection.Loc, err)
-						fields[selection.Alias] = nil
-						return
+			if selection.Name == "__typename" {
+				fields.Set(selection.Alias, object.Name)
+				continue
+			}
+			field := object.Fields[selection.Name]
+			if field != nil {
+				fieldCtx := ctx.withField(selection)
+				resolved, err := e.resolveAndExecute(fieldCtx, object.Name, field, inner.Interface(), selection)
+				if err != nil {
+					if err := fieldCtx.nullify(field.Type, err); err != nil {
+						return nil, err
 					}
-					fields[selection.Alias] = resolved
+					resolved = nil
 				}
-			}()
+				fields.Set(selection.Alias, resolved)
+			}
 		}
 
 		for _, fragment := range selectionSet.Fragments {
-			func() {
-				ctx.updatePath(true, fragment.Fragment.Name)
-				defer func() {
-					ctx.updatePath(false)
-				}()
-				if fragment.Fragment.On != typString && fragment.Fragment.On != typ.Name {
-					if _, ok := object.Interfaces[fragment.Fragment.On]; !ok {
-						return
-					}
-				}
-				resolved, err := e.executeObject(ctx, object, inner.Interface(), fragment.Fragment.SelectionSet)
-				if err != nil {
-					ctx.addErr(fragment.Loc, err)
-					return
+			if fragment.Fragment.On != typString && fragment.Fragment.On != typ.Name {
+				if _, ok := object.Interfaces[fragment.Fragment.On]; !ok {
+					continue
 				}
+			}
+			resolved, err := e.executeObject(ctx, object, inner.Interface(), fragment.Fragment.SelectionSet)
+			if err == errNullPropagated {
+				return nil, err
+			} else if err != nil {
+				ctx.addErr(fragment.Loc, err)
+				continue
+			}
 
-				for k, v := range resolved.(map[string]interface{}) {
-					fields[k] = v
+			if resolved, ok := resolved.(*OrderedMap); ok {
+				for _, k := range resolved.Keys() {
+					v, _ := resolved.Get(k)
+					fields.Set(k, v)
 				}
-				return
-			}()
-
+			}
 		}
 	}
 
@@ -219,29 +339,61 @@ func (e *Executor) executeObject(ctx *exeContext, typ *internal.Object, source i
 		return nil, nil
 	}
 
-	selections, err := Flatten(selectionSet)
+	selections, deferred, err := flatten(selectionSet, ctx.incremental != nil)
 	if err != nil {
 		return nil, err
 	}
+	e.deferFragments(ctx, typ, source, deferred)
+	return e.executeSelections(ctx, typ, source, selections, nil)
+}
 
-	fields := make(map[string]interface{})
+// executeSelections resolves the flattened selections of an object. Values in
+// prefetched, indexed like selections, were already resolved by a batch resolver.
+func (e *Executor) executeSelections(ctx *exeContext, typ *internal.Object, source interface{},
+	selections []*internal.Selection, prefetched []*batchResult) (interface{}, error) {
+	// every selection writes only its own slot, so the results can be
+	// collected without locking while the fields resolve concurrently
+	results := make([]interface{}, len(selections))
+	present := make([]bool, len(selections))
+	propagated := make([]bool, len(selections))
+	var wg sync.WaitGroup
 
 	// for every selection, resolve the value and store it in the output object
-	for _, selection := range selections {
-		func() {
-			ctx.updatePath(true, selection.Alias)
+	for i, selection := range selections {
+		i, selection := i, selection
+		ctx.fork(&wg, func() {
+			ctx := ctx.withField(selection)
+			field := typ.Fields[selection.Name]
+			// a panic, of a directive or while executing the value, fails the field alone
 			defer func() {
-				ctx.updatePath(false)
+				if panicErr := recover(); panicErr != nil {
+					var fieldType internal.Type
+					if field != nil {
+						fieldType = field.Type
+					}
+					propagated[i] = ctx.nullify(fieldType, errors.NewPanicError(panicErr, errors.Stack())) != nil
+					results[i], present[i] = nil, true
+				}
 			}()
-			field := typ.Fields[selection.Name]
 			if len(selection.Directives) > 0 {
+				var resolve internal.FieldResolve
+				switch {
+				case field != nil:
+					resolve = field.Resolve
+				case selection.Name == "__typename":
+					resolve = func(context.Context, interface{}, interface{}) (interface{}, error) {
+						return typ.Name, nil
+					}
+				default:
+					return
+				}
 				for _, directive := range selection.Directives {
-					next, result, err := directive.FnResolve(ctx, directive.ArgVals, field.Resolve, source, selection.Args)
+					next, result, err := directive.FnResolve(ctx, directive.ArgVals, resolve, source, selection.Args)
 					if err != nil {
 						ctx.addErr(directive.Loc, err)
 						return
 					}
-					fields[selection.Alias] = result
+					results[i], present[i] = result, true
 					if !next {
 						break
 					}
@@ -250,41 +402,102 @@ func (e *Executor) executeObject(ctx *exeContext, typ *internal.Object, source i
 			}
 
 			if selection.Name == "__typename" {
-				fields[selection.Alias] = typ.Name
+				results[i], present[i] = typ.Name, true
 				return
 			}
 
-			if field != nil {
-				resolved, err := e.resolveAndExecute(ctx, field, source, selection)
-				if err != nil {
-					ctx.addErr(selection.Loc, err)
-					fields[selection.Alias] = nil
-					return
+			if field == nil {
+				return
+			}
+			var resolved interface{}
+			var err error
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = abandoned(ctxErr)
+			} else if prefetched != nil && prefetched[i] != nil {
+				resolved, err = prefetched[i].value, prefetched[i].err
+				ctx.trace(typ.Name, field, prefetched[i].start, prefetched[i].end)
+				if err == nil {
+					resolved, err = e.execute(ctx, field.Type, resolved, selection.SelectionSet)
 				}
-				fields[selection.Alias] = resolved
+			} else {
+				resolved, err = e.resolveAndExecute(ctx, typ.Name, field, source, selection)
 			}
-			return
-		}()
+			if err != nil {
+				propagated[i] = ctx.nullify(field.Type, err) != nil
+				resolved = nil
+			}
+			results[i], present[i] = resolved, true
+		})
+	}
+	ctx.wait(&wg)
+
+	fields := NewOrderedMap(len(selections))
+	for i, selection := range selections {
+		// a non-null field is null, so this object is null as well
+		if propagated[i] {
+			return nil, errNullPropagated
+		}
+		if present[i] {
+			fields.Set(selection.Alias, results[i])
+		}
 	}
 	return fields, nil
 }
 
-func (e *Executor) resolveAndExecute(ctx *exeContext, field *internal.Field, source interface{},
+func (e *Executor) resolveAndExecute(ctx *exeContext, parentType string, field *internal.Field, source interface{},
 	selection *internal.Selection) (interface{}, error) {
-	value, err := safeExecuteResolver(ctx.Context, field, source, selection.Args)
+	if err := ctx.Err(); err != nil {
+		return nil, abandoned(err)
+	}
+	var value interface{}
+	var err error
+	if field.BatchResolve != nil {
+		// the parent is resolved along with the others waiting for the same field
+		load := ctx.batches.load(field, selection.Args, []interface{}{source})
+		ctx.batches.wait(load)
+		var results []interface{}
+		if results, err = load.results(); err == nil {
+			value = results[0]
+		}
+		ctx.trace(parentType, field, load.call.start, load.call.end)
+	} else {
+		start := time.Now()
+		value, err = safeExecuteResolver(ctx.Context, field, source, selection.Args)
+		ctx.trace(parentType, field, start, time.Now())
+	}
 	if err != nil {
 		return nil, err
 	}
 	return e.execute(ctx, field.Type, value, selection.SelectionSet)
 }
 
+// abandoned returns the error of a field left unresolved because the context of the operation is done,
+// coded TIMEOUT when its deadline passed and CANCELLED otherwise.
+func abandoned(err error) error {
+	code := "CANCELLED"
+	if err == context.DeadlineExceeded {
+		code = "TIMEOUT"
+	}
+	return &errors.Error{Message: "field abandoned: " + err.Error(), Code: code, Err: err}
+}
+
+func safeExecuteBatchResolver(ctx context.Context, field *internal.Field, sources []interface{}, args interface{}) (results []interface{}, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			results, err = nil, errors.NewPanicError(panicErr, errors.Stack())
+		}
+	}()
+	results, err = field.BatchResolve(ctx, sources, args)
+	if err == nil && len(results) != len(sources) {
+		err = fmt.Errorf("batch resolver returned %d results for %d sources", len(results), len(sources))
+	}
+	return results, err
+}
+
 func safeExecuteResolver(ctx context.Context, field *internal.Field, source, args interface{}) (result interface{}, err error) {
 	defer func() {
 		if panicErr := recover(); panicErr != nil {
-			const size = 64 << 10
-			buf := make([]byte, size)
-			buf = buf[:runtime.Stack(buf, false)]
-			result, err = nil, fmt.Errorf("graphql: panic: %v\n%s", panicErr, buf)
+			result, err = nil, errors.NewPanicError(panicErr, errors.Stack())
 		}
 	}()
 	return field.Resolve(ctx, source, args)
@@ -299,21 +512,162 @@ func (e *Executor) executeList(ctx *exeContext, typ *internal.List, source inter
 
 	// iterate over arbitrary slice types using reflect
 	slice := reflect.ValueOf(source)
+	if ctx.stream != nil && slice.Len() > ctx.stream.InitialCount {
+		e.streamItems(ctx, typ, slice.Slice(ctx.stream.InitialCount, slice.Len()), selectionSet)
+		slice = slice.Slice(0, ctx.stream.InitialCount)
+	}
 	items := make([]interface{}, slice.Len())
+	errs := make([]error, slice.Len())
+	var wg sync.WaitGroup
 
-	// resolve every element in the slice
-	for i := 0; i < slice.Len(); i++ {
-		value := slice.Index(i)
-		resolved, err := e.execute(ctx, typ.Type, value.Interface(), selectionSet)
+	elemTyp := typ.Type
+	if nonNull, ok := elemTyp.(*internal.NonNull); ok {
+		elemTyp = nonNull.Type
+	}
+	if object, ok := elemTyp.(*internal.Object); ok && slice.Len() > 1 {
+		selections, deferred, prefetched, err := e.prefetchBatches(ctx, object, slice, selectionSet)
 		if err != nil {
 			return nil, err
 		}
-		items[i] = resolved
+		if prefetched != nil {
+			for i := 0; i < slice.Len(); i++ {
+				if err := ctx.Err(); err != nil {
+					ctx.wait(&wg)
+					return nil, abandoned(err)
+				}
+				i, value := i, slice.Index(i).Interface()
+				ctx.fork(&wg, func() {
+					defer func() {
+						if panicErr := recover(); panicErr != nil {
+							items[i], errs[i] = nil, errors.NewPanicError(panicErr, errors.Stack())
+						}
+					}()
+					items[i], errs[i] = e.executeBatchedObject(ctx.withPath(i), typ.Type, object, value, selections, deferred, prefetched[i])
+				})
+			}
+			ctx.wait(&wg)
+			return e.completeList(ctx, typ, items, errs)
+		}
+	}
+
+	// resolve every element in the slice, the list is abandoned once the context is done
+	for i := 0; i < slice.Len(); i++ {
+		if err := ctx.Err(); err != nil {
+			ctx.wait(&wg)
+			return nil, abandoned(err)
+		}
+		i, value := i, slice.Index(i)
+		ctx.fork(&wg, func() {
+			defer func() {
+				if panicErr := recover(); panicErr != nil {
+					items[i], errs[i] = nil, errors.NewPanicError(panicErr, errors.Stack())
+				}
+			}()
+			items[i], errs[i] = e.execute(ctx.withPath(i), typ.Type, value.Interface(), selectionSet)
+		})
 	}
+	ctx.wait(&wg)
 
+	return e.completeList(ctx, typ, items, errs)
+}
+
+// completeList nulls the items which failed, or the whole list if its items are non-null.
+func (e *Executor) completeList(ctx *exeContext, typ *internal.List, items []interface{}, errs []error) (interface{}, error) {
+	var propagated error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		// every failed item is reported, even when the first one already nulls the list
+		if err := ctx.withPath(i).nullify(typ.Type, err); err != nil {
+			propagated = err
+		}
+		items[i] = nil
+	}
+	if propagated != nil {
+		return nil, propagated
+	}
 	return items, nil
 }
 
+type batchResult struct {
+	value interface{}
+	err   error
+	// start and end time the call of the batch resolver
+	start, end time.Time
+}
+
+// prefetchBatches calls every batch resolver selected on the elements of a list once for the whole list,
+// and returns the results indexed by element, then by selection. prefetched is nil when nothing was batched.
+func (e *Executor) prefetchBatches(ctx *exeContext, typ *internal.Object, slice reflect.Value,
+	selectionSet *internal.SelectionSet) (selections []*internal.Selection, deferred []*internal.FragmentSpread,
+	prefetched [][]*batchResult, err error) {
+	selections, deferred, err = flatten(selectionSet, ctx.incremental != nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var sources []interface{}
+	var indexes []int
+	for i := 0; i < slice.Len(); i++ {
+		value := slice.Index(i)
+		if unwrap(value.Interface()) == nil {
+			continue
+		}
+		sources = append(sources, value.Interface())
+		indexes = append(indexes, i)
+	}
+
+	// the loads of every batched field are waited for at once
+	loads := make([]*batchLoad, len(selections))
+	var batched []*batchLoad
+	for j, selection := range selections {
+		field := typ.Fields[selection.Name]
+		if field == nil || field.BatchResolve == nil || len(selection.Directives) > 0 {
+			continue
+		}
+		loads[j] = ctx.batches.load(field, selection.Args, sources)
+		batched = append(batched, loads[j])
+	}
+	if len(batched) == 0 {
+		return selections, deferred, nil, nil
+	}
+	ctx.batches.wait(batched...)
+
+	prefetched = make([][]*batchResult, slice.Len())
+	for i := range prefetched {
+		prefetched[i] = make([]*batchResult, len(selections))
+	}
+	for j, load := range loads {
+		if load == nil {
+			continue
+		}
+		results, err := load.results()
+		start, end := load.call.start, load.call.end
+		for k, i := range indexes {
+			if err != nil {
+				prefetched[i][j] = &batchResult{err: err, start: start, end: end}
+			} else {
+				prefetched[i][j] = &batchResult{value: results[k], start: start, end: end}
+			}
+		}
+	}
+	return selections, deferred, prefetched, nil
+}
+
+// executeBatchedObject is like execute for a list element, with some fields of the object already resolved.
+func (e *Executor) executeBatchedObject(ctx *exeContext, typ internal.Type, object *internal.Object, source interface{},
+	selections []*internal.Selection, deferred []*internal.FragmentSpread, prefetched []*batchResult) (interface{}, error) {
+	if value := reflect.ValueOf(source); value.Kind() == reflect.Ptr && value.IsNil() {
+		if _, ok := typ.(*internal.NonNull); ok {
+			return nil, fmt.Errorf("cannot return null for non-nullable field %v", object)
+		}
+		return nil, nil
+	}
+	e.deferFragments(ctx, object, source, deferred)
+	return e.executeSelections(ctx, object, source, selections, prefetched)
+}
+
 // executeInterface resolves an interface query
 func (e *Executor) executeInterface(ctx *exeContext, typ *internal.Interface, source interface{},
 	selectionSet *internal.SelectionSet) (interface{}, error) {
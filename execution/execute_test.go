@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
 	"github.com/shyptr/graphql/schemabuilder"
 	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type Pet interface {
@@ -621,6 +625,586 @@ func TestExecutor_Execute(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("Execute: Keeps selection order", func(t *testing.T) {
+		type Pair struct {
+			Left  int `graphql:"left"`
+			Right int `graphql:"right"`
+		}
+		build := schemabuilder.NewSchema()
+		build.Object("Pair", Pair{})
+		build.Query().FieldFunc("zebra", func() string { return "z" }, "")
+		build.Query().FieldFunc("apple", func() string { return "a" }, "")
+		build.Query().FieldFunc("pair", func() Pair { return Pair{1, 2} }, "")
+		schema := build.MustBuild()
+
+		result, err := execution.Do(schema, execution.Params{Query: `
+      {
+        zebra
+        pair { right ...F }
+        first: apple
+        ...G
+      }
+      fragment F on Pair { left right }
+      fragment G on Query { zebra pair { left } }
+    `})
+		assert.Equal(t, errors.MultiError(nil), err)
+		marshal, err2 := json.Marshal(result)
+		assert.NoError(t, err2)
+		assert.Equal(t, `{"zebra":"z","pair":{"right":2,"left":1},"first":"a"}`, string(marshal))
+	})
+
+	t.Run("Execute: Handles null propagation", func(t *testing.T) {
+		type Leaf struct {
+			ID int `graphql:"id"`
+		}
+		type Branch struct {
+			Name string `graphql:"name"`
+		}
+		build := schemabuilder.NewSchema()
+		leaf := build.Object("Leaf", Leaf{})
+		leaf.FieldFunc("value", func(l Leaf) (*string, error) {
+			if l.ID == 2 {
+				return nil, fmt.Errorf("leaf %d failed", l.ID)
+			}
+			return schemabuilder.StrPtr(fmt.Sprint("leaf", l.ID)), nil
+		}, "", schemabuilder.NonNullField)
+		branch := build.Object("Branch", Branch{})
+		branch.FieldFunc("leaf", func() *Leaf { return &Leaf{2} }, "")
+		branch.FieldFunc("nullableLeaves", func() []*Leaf { return []*Leaf{{1}, {2}, {3}} }, "")
+		branch.FieldFunc("leaves", func() []Leaf { return []Leaf{{1}, {2}, {3}} }, "")
+		build.Query().FieldFunc("branch", func() *Branch { return &Branch{"b"} }, "")
+		build.Query().FieldFunc("leaf", func() Leaf { return Leaf{2} }, "")
+		schema := build.MustBuild()
+
+		run := func(query string) (string, errors.MultiError) {
+			result, err := execution.Do(schema, execution.Params{Query: query})
+			marshal, err2 := json.Marshal(result)
+			assert.NoError(t, err2)
+			return string(marshal), err
+		}
+
+		t.Run("nulls the nearest nullable parent and keeps siblings", func(t *testing.T) {
+			data, err := run("{ branch { name leaf { id value } } }")
+			assert.JSONEq(t, `{"branch": {"name": "b", "leaf": null}}`, data)
+			assert.Len(t, err, 1)
+			assert.Equal(t, "leaf 2 failed", err[0].Message)
+			assert.Equal(t, []interface{}{"branch", "leaf", "value"}, err[0].Path)
+		})
+
+		t.Run("nulls only the failed item of a nullable list", func(t *testing.T) {
+			data, err := run("{ branch { nullableLeaves { value } } }")
+			assert.JSONEq(t, `{"branch": {"nullableLeaves": [{"value": "leaf1"}, null, {"value": "leaf3"}]}}`, data)
+			assert.Len(t, err, 1)
+			assert.Equal(t, []interface{}{"branch", "nullableLeaves", 1, "value"}, err[0].Path)
+		})
+
+		t.Run("nulls a list of non-null items", func(t *testing.T) {
+			data, err := run("{ branch { name leaves { value } } }")
+			assert.JSONEq(t, `{"branch": {"name": "b", "leaves": null}}`, data)
+			assert.Len(t, err, 1)
+			assert.Equal(t, []interface{}{"branch", "leaves", 1, "value"}, err[0].Path)
+		})
+
+		t.Run("nulls data when every parent is non-null", func(t *testing.T) {
+			data, err := run("{ branch { name } leaf { value } }")
+			assert.Equal(t, "null", data)
+			assert.Len(t, err, 1)
+			assert.Equal(t, []interface{}{"leaf", "value"}, err[0].Path)
+		})
+	})
+
+	t.Run("Execute: Handles concurrent resolution", func(t *testing.T) {
+		t.Run("resolves query fields concurrently", func(t *testing.T) {
+			var a, b sync.WaitGroup
+			a.Add(1)
+			b.Add(1)
+			wait := func(wg *sync.WaitGroup) bool {
+				done := make(chan struct{})
+				go func() { wg.Wait(); close(done) }()
+				select {
+				case <-done:
+					return true
+				case <-time.After(time.Second):
+					return false
+				}
+			}
+			build := schemabuilder.NewSchema()
+			build.Query().FieldFunc("a", func() bool { a.Done(); return wait(&b) }, "")
+			build.Query().FieldFunc("b", func() bool { b.Done(); return wait(&a) }, "")
+			schema := build.MustBuild()
+			result, err := execution.Do(schema, execution.Params{Query: "{ a b }"})
+			assert.Equal(t, errors.MultiError(nil), err)
+			assert.Equal(t, map[string]interface{}{"a": true, "b": true}, result.(*execution.OrderedMap).Map())
+		})
+
+		t.Run("resolves mutation root fields serially", func(t *testing.T) {
+			var mu sync.Mutex
+			var order []string
+			record := func(name string, delay time.Duration) func() string {
+				return func() string {
+					time.Sleep(delay)
+					mu.Lock()
+					defer mu.Unlock()
+					order = append(order, name)
+					return name
+				}
+			}
+			build := schemabuilder.NewSchema()
+			build.Query().FieldFunc("noop", func() bool { return true }, "")
+			build.Mutation().FieldFunc("first", record("first", 30*time.Millisecond), "")
+			build.Mutation().FieldFunc("second", record("second", 10*time.Millisecond), "")
+			build.Mutation().FieldFunc("third", record("third", 0), "")
+			schema := build.MustBuild()
+			result, err := execution.Do(schema, execution.Params{Query: "mutation { first second third }"})
+			assert.Equal(t, errors.MultiError(nil), err)
+			assert.Equal(t, map[string]interface{}{"first": "first", "second": "second", "third": "third"}, result.(*execution.OrderedMap).Map())
+			assert.Equal(t, []string{"first", "second", "third"}, order)
+		})
+
+		t.Run("respects MaxConcurrency", func(t *testing.T) {
+			var running, peak int32
+			resolve := func() []int {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				return []int{1, 2, 3}
+			}
+			build := schemabuilder.NewSchema()
+			build.Query().FieldFunc("a", resolve, "")
+			build.Query().FieldFunc("b", resolve, "")
+			build.Query().FieldFunc("c", resolve, "")
+			build.Query().FieldFunc("d", resolve, "")
+			schema := build.MustBuild()
+			doc, err := internal.Parse("{ a b c d }")
+			assert.NoError(t, err)
+			_, selectionSet, err := execution.ApplySelectionSet(schema, doc, "", nil)
+			assert.NoError(t, err)
+
+			executor := &execution.Executor{MaxConcurrency: 2}
+			result, errs := executor.Execute(context.Background(), schema.Query, nil, selectionSet)
+			assert.Equal(t, errors.MultiError(nil), errs)
+			assert.Equal(t, map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+				"b": []interface{}{1, 2, 3},
+				"c": []interface{}{1, 2, 3},
+				"d": []interface{}{1, 2, 3},
+			}, result.(*execution.OrderedMap).Map())
+			assert.True(t, peak <= 2, "peak concurrency %d exceeds limit", peak)
+		})
+
+		t.Run("recovers panics of concurrent fields", func(t *testing.T) {
+			build := schemabuilder.NewSchema()
+			build.Query().FieldFunc("a", func() string { return "a" }, "")
+			build.Query().FieldFunc("b", func() *string { return nil }, "")
+			build.Directive("boom", []string{"FIELD"}, func(args struct {
+				If bool `graphql:"if"`
+			}, field func() (interface{}, error)) (interface{}, error) {
+				if args.If {
+					panic("boom")
+				}
+				return field()
+			})
+			schema := build.MustBuild()
+
+			result, err := execution.Do(schema, execution.Params{Query: "{ a b @boom(if: true) __typename @boom(if: false) }"})
+			marshal, err2 := json.Marshal(result)
+			assert.NoError(t, err2)
+			assert.JSONEq(t, `{"a": "a", "b": null, "__typename": "Query"}`, string(marshal))
+			if assert.Len(t, err, 1) {
+				assert.Equal(t, []interface{}{"b"}, err[0].Path)
+				assert.Equal(t, "internal error", err[0].Message)
+				assert.IsType(t, &errors.PanicError{}, err[0].ResolverError)
+			}
+		})
+	})
+
+	t.Run("Execute: Handles batch resolvers", func(t *testing.T) {
+		type Item struct {
+			ID int `graphql:"id"`
+		}
+		items := []*Item{{1}, {2}, {3}}
+
+		t.Run("calls a batch resolver once per depth", func(t *testing.T) {
+			var calls int32
+			build := schemabuilder.NewSchema()
+			item := build.Object("Item", Item{})
+			item.FieldFunc("double", func(items []*Item) []int {
+				atomic.AddInt32(&calls, 1)
+				res := make([]int, len(items))
+				for i, item := range items {
+					res[i] = item.ID * 2
+				}
+				return res
+			}, "")
+			item.FieldFunc("name", func(items []Item) map[Item]string {
+				atomic.AddInt32(&calls, 1)
+				return map[Item]string{{1}: "one", {3}: "three"}
+			}, "")
+			build.Query().FieldFunc("items", func() []*Item { return items }, "")
+			build.Query().FieldFunc("item", func() *Item { return items[1] }, "")
+			schema := build.MustBuild()
+
+			result, err := execution.Do(schema, execution.Params{Query: "{ items { id double name } item { double } }"})
+			assert.Equal(t, errors.MultiError(nil), err)
+			marshal, err2 := json.Marshal(result)
+			assert.NoError(t, err2)
+			assert.JSONEq(t, `{
+	"items": [
+		{"id": 1, "double": 2, "name": "one"},
+		{"id": 2, "double": 4, "name": ""},
+		{"id": 3, "double": 6, "name": "three"}
+	],
+	"item": {"double": 4}
+}`, string(marshal))
+			// double is resolved for the items and the item at once
+			assert.Equal(t, int32(2), calls)
+		})
+
+		t.Run("looks the results of values up with the pointers given to the batch resolver", func(t *testing.T) {
+			build := schemabuilder.NewSchema()
+			build.Object("Item", Item{}).FieldFunc("name", func(items []*Item) map[*Item]string {
+				names := make(map[*Item]string, len(items))
+				for _, item := range items {
+					names[item] = fmt.Sprint("item", item.ID)
+				}
+				return names
+			}, "")
+			build.Query().FieldFunc("items", func() []Item { return []Item{{1}, {2}} }, "")
+			build.Query().FieldFunc("item", func() Item { return Item{3} }, "")
+			schema := build.MustBuild()
+
+			result, err := execution.Do(schema, execution.Params{Query: "{ items { name } item { name } }"})
+			assert.Equal(t, errors.MultiError(nil), err)
+			marshal, err2 := json.Marshal(result)
+			assert.NoError(t, err2)
+			assert.JSONEq(t, `{"items": [{"name": "item1"}, {"name": "item2"}], "item": {"name": "item3"}}`, string(marshal))
+		})
+
+		t.Run("batches the parents at the same depth across lists and abstract types", func(t *testing.T) {
+			type Shelf struct {
+				Items []*Item `graphql:"items"`
+			}
+			type Thing struct {
+				*Item
+				*Shelf
+			}
+			var mu sync.Mutex
+			var calls [][]int
+			build := schemabuilder.NewSchema()
+			build.Object("Item", Item{}).FieldFunc("double", func(items []*Item) []int {
+				ids := make([]int, len(items))
+				res := make([]int, len(items))
+				for i, item := range items {
+					ids[i], res[i] = item.ID, item.ID*2
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, ids)
+				return res
+			}, "")
+			build.Object("Shelf", Shelf{})
+			build.Union("Thing", Thing{}, "")
+			build.Query().FieldFunc("shelves", func() []*Shelf {
+				return []*Shelf{{Items: []*Item{{1}, {2}}}, {Items: []*Item{{3}}}, {Items: []*Item{{4}, {5}}}}
+			}, "")
+			build.Query().FieldFunc("things", func() []Thing {
+				return []Thing{{Item: &Item{6}}, {Shelf: &Shelf{}}, {Item: &Item{7}}}
+			}, "")
+			schema := build.MustBuild()
+
+			const query = "{ shelves { items { double } } things { ...ItemDouble } } fragment ItemDouble on Item { double }"
+			for _, executor := range []*execution.Executor{{}, {MaxConcurrency: 1}} {
+				calls = nil
+				doc, err := internal.Parse(query)
+				assert.NoError(t, err)
+				_, selectionSet, err := execution.ApplySelectionSet(schema, doc, "", nil)
+				assert.NoError(t, err)
+				result, errs := executor.Execute(context.Background(), schema.Query, nil, selectionSet)
+				assert.Equal(t, errors.MultiError(nil), errs)
+				marshal, err := json.Marshal(result)
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{
+	"shelves": [
+		{"items": [{"double": 2}, {"double": 4}]},
+		{"items": [{"double": 6}]},
+		{"items": [{"double": 8}, {"double": 10}]}
+	],
+	"things": [{"double": 12}, {}, {"double": 14}]
+}`, string(marshal))
+				// resolving fields one after another, the parents can't wait for each other
+				if executor.MaxConcurrency == 0 && assert.Len(t, calls, 1) {
+					assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7}, calls[0])
+				}
+			}
+		})
+
+		t.Run("reports the error of a batch resolver on every element", func(t *testing.T) {
+			build := schemabuilder.NewSchema()
+			build.Object("Item", Item{}).FieldFunc("fail", func(items []*Item) ([]*string, error) {
+				return nil, fmt.Errorf("batch failed")
+			}, "")
+			build.Query().FieldFunc("items", func() []*Item { return items[:2] }, "")
+			schema := build.MustBuild()
+
+			result, err := execution.Do(schema, execution.Params{Query: "{ items { id fail } }"})
+			assert.Len(t, err, 2)
+			assert.Equal(t, "batch failed", err[0].Message)
+			marshal, err2 := json.Marshal(result)
+			assert.NoError(t, err2)
+			assert.JSONEq(t, `{"items": [{"id": 1, "fail": null}, {"id": 2, "fail": null}]}`, string(marshal))
+		})
+
+		t.Run("shares loaders within a request", func(t *testing.T) {
+			var fetched [][]interface{}
+			var mu sync.Mutex
+			fetch := func(ctx context.Context, keys []interface{}) ([]interface{}, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				fetched = append(fetched, keys)
+				res := make([]interface{}, len(keys))
+				for i, key := range keys {
+					res[i] = fmt.Sprint("item", key)
+				}
+				return res, nil
+			}
+			build := schemabuilder.NewSchema()
+			build.Object("Item", Item{}).FieldFunc("name", func(ctx context.Context, item *Item) (string, error) {
+				name, err := schemabuilder.GetLoader(ctx, "name", fetch).Load(ctx, item.ID)
+				if err != nil {
+					return "", err
+				}
+				return name.(string), nil
+			}, "")
+			build.Query().FieldFunc("items", func() []*Item { return []*Item{{1}, {1}} }, "")
+			schema := build.MustBuild()
+
+			result, err := execution.Do(schema, execution.Params{Query: "{ items { name } again: items { name } }"})
+			assert.Equal(t, errors.MultiError(nil), err)
+			marshal, err2 := json.Marshal(result)
+			assert.NoError(t, err2)
+			assert.JSONEq(t, `{
+	"items": [{"name": "item1"}, {"name": "item1"}],
+	"again": [{"name": "item1"}, {"name": "item1"}]
+}`, string(marshal))
+			assert.Equal(t, [][]interface{}{{1}}, fetched)
+
+			_, err = execution.Do(schema, execution.Params{Query: "{ items { name } }"})
+			assert.Equal(t, errors.MultiError(nil), err)
+			assert.Len(t, fetched, 2, "loaders must not outlive a request")
+		})
+	})
+
+	t.Run("Execute: Handles incremental delivery", func(t *testing.T) {
+		type Item struct {
+			ID int `graphql:"id"`
+		}
+		build := schemabuilder.NewSchema()
+		item := build.Object("Item", Item{})
+		item.FieldFunc("slow", func(item *Item) string { return fmt.Sprint("slow", item.ID) }, "")
+		item.FieldFunc("broken", func() (string, error) { return "", errors.New("broken") }, "")
+		build.Query().FieldFunc("item", func() *Item { return &Item{1} }, "")
+		build.Query().FieldFunc("items", func() []*Item { return []*Item{{1}, {2}, {3}} }, "")
+		schema := build.MustBuild()
+
+		execute := func(query string) (string, []string) {
+			doc, err := internal.Parse(query)
+			assert.NoError(t, err)
+			op, selectionSet, err := execution.ApplySelectionSet(schema, doc, "", nil)
+			assert.NoError(t, err)
+			data, errs, results := (&execution.Executor{}).ExecuteIncremental(context.Background(), op, schema.Query, nil, selectionSet)
+			assert.Equal(t, errors.MultiError(nil), errs)
+			initial, err := json.Marshal(data)
+			assert.NoError(t, err)
+			var subsequent []string
+			if results == nil {
+				return string(initial), subsequent
+			}
+			for result := range results {
+				marshal, err := json.Marshal(result)
+				assert.NoError(t, err)
+				subsequent = append(subsequent, string(marshal))
+			}
+			return string(initial), subsequent
+		}
+
+		t.Run("defers fragments", func(t *testing.T) {
+			initial, subsequent := execute(`{ item { id ...F @defer(label: "slow") } } fragment F on Item { slow }`)
+			assert.JSONEq(t, `{"item": {"id": 1}}`, initial)
+			assert.Equal(t, []string{`{"data":{"slow":"slow1"},"path":["item"],"label":"slow"}`}, subsequent)
+		})
+
+		t.Run("streams lists", func(t *testing.T) {
+			initial, subsequent := execute(`{ items @stream(initialCount: 1) { id } }`)
+			assert.JSONEq(t, `{"items": [{"id": 1}]}`, initial)
+			assert.Equal(t, []string{
+				`{"items":[{"id":2}],"path":["items",1]}`,
+				`{"items":[{"id":3}],"path":["items",2]}`,
+			}, subsequent)
+		})
+
+		t.Run("delivers a payload after the one it belongs to", func(t *testing.T) {
+			initial, subsequent := execute(`{ items @stream(initialCount: 2) { id ... on Item @defer { slow } } }`)
+			assert.JSONEq(t, `{"items": [{"id": 1}, {"id": 2}]}`, initial)
+			assert.ElementsMatch(t, []string{
+				`{"data":{"slow":"slow1"},"path":["items",0]}`,
+				`{"data":{"slow":"slow2"},"path":["items",1]}`,
+				`{"items":[{"id":3}],"path":["items",2]}`,
+				`{"data":{"slow":"slow3"},"path":["items",2]}`,
+			}, subsequent)
+			index := func(payload string) int {
+				for i, s := range subsequent {
+					if s == payload {
+						return i
+					}
+				}
+				return -1
+			}
+			assert.True(t, index(`{"items":[{"id":3}],"path":["items",2]}`) < index(`{"data":{"slow":"slow3"},"path":["items",2]}`))
+		})
+
+		t.Run("drops the payloads of nulled objects", func(t *testing.T) {
+			doc, err := internal.Parse(`{ item { id ... on Item @defer { slow } broken } items @stream(initialCount: 3) { id } }`)
+			assert.NoError(t, err)
+			op, selectionSet, err := execution.ApplySelectionSet(schema, doc, "", nil)
+			assert.NoError(t, err)
+			data, errs, results := (&execution.Executor{}).ExecuteIncremental(context.Background(), op, schema.Query, nil, selectionSet)
+			initial, err := json.Marshal(data)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"item": null, "items": [{"id": 1}, {"id": 2}, {"id": 3}]}`, string(initial))
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, []interface{}{"item", "broken"}, errs[0].Path)
+			}
+			assert.Nil(t, results)
+		})
+
+		t.Run("ignores disabled directives", func(t *testing.T) {
+			initial, subsequent := execute(`{ items @stream(if: false) { id ... on Item @defer(if: false) { slow } } }`)
+			assert.JSONEq(t, `{"items": [{"id": 1, "slow": "slow1"}, {"id": 2, "slow": "slow2"}, {"id": 3, "slow": "slow3"}]}`, initial)
+			assert.Empty(t, subsequent)
+		})
+
+		t.Run("resolves everything at once otherwise", func(t *testing.T) {
+			result, err := execution.Do(schema, execution.Params{Query: `{ items @stream { id ... on Item @defer { slow } } }`})
+			assert.Equal(t, errors.MultiError(nil), err)
+			marshal, err2 := json.Marshal(result)
+			assert.NoError(t, err2)
+			assert.JSONEq(t, `{"items": [{"id": 1, "slow": "slow1"}, {"id": 2, "slow": "slow2"}, {"id": 3, "slow": "slow3"}]}`, string(marshal))
+		})
+
+		t.Run("streams only lists", func(t *testing.T) {
+			_, err := execution.Do(schema, execution.Params{Query: `{ item @stream { id } }`})
+			if assert.Len(t, err, 1) {
+				assert.Equal(t, "StreamOnListField", err[0].Rule)
+			}
+		})
+	})
+
+	t.Run("Execute: Handles resolver errors", func(t *testing.T) {
+		build := schemabuilder.NewSchema()
+		build.Query().FieldFunc("coded", func() (*string, error) {
+			return nil, &errors.Error{Message: "not allowed", Code: "FORBIDDEN", Data: map[string]interface{}{"role": "admin"}}
+		}, "")
+		build.Query().FieldFunc("wrapped", func() (*string, error) {
+			return nil, fmt.Errorf("wrapped: %w", errors.Errorf("NOT_FOUND", "no %s", "item"))
+		}, "")
+		build.Query().FieldFunc("panics", func() *string { panic("secret") }, "")
+		schema := build.MustBuild()
+
+		result, err := execution.Do(schema, execution.Params{Query: "{ coded wrapped panics }"})
+		marshal, err2 := json.Marshal(result)
+		assert.NoError(t, err2)
+		assert.JSONEq(t, `{"coded": null, "wrapped": null, "panics": null}`, string(marshal))
+		if !assert.Len(t, err, 3) {
+			return
+		}
+		// fields resolve concurrently, errors are found by path
+		errs := make(map[string]*errors.GraphQLError)
+		for _, err := range err {
+			errs[err.Path[0].(string)] = err
+		}
+		assert.Equal(t, "not allowed", errs["coded"].Message)
+		assert.Equal(t, map[string]interface{}{"code": "FORBIDDEN", "role": "admin"}, errs["coded"].Extensions)
+		assert.Equal(t, "wrapped: no item", errs["wrapped"].Message)
+		assert.Equal(t, map[string]interface{}{"code": "NOT_FOUND"}, errs["wrapped"].Extensions)
+
+		// the panic is not leaked, but kept for logging under its correlation ID
+		assert.Equal(t, "internal error", errs["panics"].Message)
+		panicErr, ok := errs["panics"].ResolverError.(*errors.PanicError)
+		if assert.True(t, ok) {
+			assert.Equal(t, map[string]interface{}{"code": "INTERNAL_SERVER_ERROR", "correlationId": panicErr.ID}, errs["panics"].Extensions)
+			assert.NotEmpty(t, panicErr.ID)
+			assert.Equal(t, "secret", panicErr.Value)
+			assert.Contains(t, panicErr.Detail(), "execute_test.go")
+		}
+	})
+
+	t.Run("Execute: Abandons fields once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		build := schemabuilder.NewSchema()
+		build.Query().FieldFunc("a", func() string { return "a" }, "")
+		build.Mutation().FieldFunc("first", func() string {
+			cancel()
+			return "first"
+		}, "")
+		build.Mutation().FieldFunc("second", func() *string {
+			t.Error("second is resolved after the context is cancelled")
+			return nil
+		}, "")
+		build.Mutation().FieldFunc("third", func() string { return "third" }, "")
+		schema := build.MustBuild()
+
+		// the root fields of a mutation are resolved in order
+		result, err := execution.Do(schema, execution.Params{Query: "mutation { first second third }", Context: ctx})
+		marshal, err2 := json.Marshal(result)
+		assert.NoError(t, err2)
+		assert.JSONEq(t, `null`, string(marshal))
+		if !assert.Len(t, err, 2) {
+			return
+		}
+		for i, path := range []string{"second", "third"} {
+			assert.Equal(t, []interface{}{path}, err[i].Path)
+			assert.Equal(t, "field abandoned: context canceled", err[i].Message)
+			assert.Equal(t, map[string]interface{}{"code": "CANCELLED"}, err[i].Extensions)
+		}
+
+		// the items of a list are abandoned as well
+		type Item struct {
+			ID int `graphql:"id"`
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		build = schemabuilder.NewSchema()
+		build.Object("Item", Item{}).FieldFunc("name", func(item Item) string {
+			if item.ID > 1 {
+				t.Errorf("item %d is resolved after the context is cancelled", item.ID)
+			}
+			cancel()
+			return "name"
+		}, "")
+		build.Query().FieldFunc("items", func() []Item { return []Item{{1}, {2}, {3}} }, "")
+		schema = build.MustBuild()
+		doc, err3 := internal.Parse("{ items { name } }")
+		assert.NoError(t, err3)
+		_, selectionSet, err3 := execution.ApplySelectionSet(schema, doc, "", nil)
+		assert.NoError(t, err3)
+		result, err = (&execution.Executor{MaxConcurrency: 1}).Execute(ctx, schema.Query, nil, selectionSet)
+		marshal, err2 = json.Marshal(result)
+		assert.NoError(t, err2)
+		assert.JSONEq(t, `{"items": null}`, string(marshal))
+		if assert.Len(t, err, 1) {
+			assert.Equal(t, []interface{}{"items"}, err[0].Path)
+			assert.Equal(t, "field abandoned: context canceled", err[0].Message)
+			assert.Equal(t, map[string]interface{}{"code": "CANCELLED"}, err[0].Extensions)
+		}
+	})
 }
 
 func check(t *testing.T, testType interface{}, testData interface{}, expected interface{}) {
@@ -635,5 +1219,5 @@ func check(t *testing.T, testType interface{}, testData interface{}, expected in
 		Context:       context.WithValue(context.Background(), "test", testData),
 	})
 	assert.Equal(t, errors.MultiError(nil), err)
-	assert.Equal(t, expected, result)
+	assert.Equal(t, expected, result.(*execution.OrderedMap).Map())
 }
@@ -0,0 +1,177 @@
+package execution
+
+import (
+	"context"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"reflect"
+	"sync"
+)
+
+// IncrementalResult is a subsequent payload of an operation using @defer or @stream.
+// Data holds the selections of a deferred fragment, and Items the next items of a
+// streamed list. Path is the path of the object the fragment is spread in, or of the item.
+type IncrementalResult struct {
+	Data   interface{}       `json:"data,omitempty"`
+	Items  []interface{}     `json:"items,omitempty"`
+	Path   []interface{}     `json:"path"`
+	Label  string            `json:"label,omitempty"`
+	Errors errors.MultiError `json:"errors,omitempty"`
+}
+
+// incremental is shared by the initial execution of an operation and all its subsequent payloads.
+type incremental struct {
+	wg      sync.WaitGroup
+	results chan *IncrementalResult
+}
+
+// ExecuteIncremental is like ExecuteOperation, but the fragments marked with @defer and the items
+// of lists marked with @stream after their initialCount first ones are left out of the response.
+// They are executed after it and sent on the returned channel, which is closed once all of
+// them are delivered. The channel is nil when nothing was left out.
+//
+// A subsequent payload is only sent once the payload it belongs to is sent, the channel
+// must be drained until it is closed or the context is done. Fragments spread on unions
+// are never deferred. The payloads under a value nulled by an error are dropped. The resolvers
+// of subsequent payloads are traced along with the others, the tracing is complete once the
+// channel is closed.
+func (e *Executor) ExecuteIncremental(ctx context.Context, operation ast.OperationType, typ internal.Type, source interface{},
+	selectionSet *internal.SelectionSet) (interface{}, errors.MultiError, <-chan *IncrementalResult) {
+	exeCtx := e.newContext(ctx, operation)
+	exeCtx.incremental = &incremental{results: make(chan *IncrementalResult)}
+	response, errs := e.run(exeCtx, typ, source, selectionSet)
+	// when the whole response is null, there is nothing left to complete
+	if response == nil || len(exeCtx.pending) == 0 {
+		return response, errs, nil
+	}
+	exeCtx.startPending()
+	go func() {
+		exeCtx.incremental.wg.Wait()
+		close(exeCtx.incremental.results)
+	}()
+	return response, errs, exeCtx.incremental.results
+}
+
+// pendingPayload is a subsequent payload waiting for the payload it belongs to, at path.
+type pendingPayload struct {
+	path []interface{}
+	job  func()
+}
+
+// later runs job once the payload being executed by e is delivered.
+func (e *exeContext) later(job func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pending = append(e.pending, pendingPayload{path: e.path, job: job})
+}
+
+// dropPending drops the subsequent payloads at path or under it, once the value at path is null.
+func (e *exeContext) dropPending(path []interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	pending := e.pending[:0]
+	for _, payload := range e.pending {
+		if !hasPrefix(payload.path, path) {
+			pending = append(pending, payload)
+		}
+	}
+	e.pending = pending
+}
+
+// hasPrefix reports whether path begins with prefix.
+func hasPrefix(path, prefix []interface{}) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// startPending starts the jobs that were waiting for the payload of e to be delivered.
+func (e *exeContext) startPending() {
+	e.mu.Lock()
+	pending := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	for _, payload := range pending {
+		e.incremental.wg.Add(1)
+		go func(job func()) {
+			defer e.incremental.wg.Done()
+			job()
+		}(payload.job)
+	}
+}
+
+// subsequent returns a context at the same path for executing a subsequent payload,
+// which collects its own errors and batches its own fields, and shares the tracing.
+func (e *exeContext) subsequent() *exeContext {
+	return &exeContext{
+		Context: e.Context,
+		exeState: &exeState{
+			workers:     e.workers,
+			batches:     newBatcher(e.Context),
+			tracing:     e.tracing,
+			incremental: e.incremental,
+		},
+		path: e.path,
+		loc:  e.loc,
+	}
+}
+
+// deliver sends result with the errors of the execution of e, then starts the payloads
+// found by that execution. Nothing more is delivered once the context is done.
+func (e *exeContext) deliver(result *IncrementalResult) {
+	e.mu.Lock()
+	result.Errors = e.errs
+	e.mu.Unlock()
+	select {
+	case e.incremental.results <- result:
+		e.startPending()
+	case <-e.Done():
+	}
+}
+
+// deferFragments executes the deferred fragments spread in an object of type typ after the current payload.
+func (e *Executor) deferFragments(ctx *exeContext, typ *internal.Object, source interface{}, fragments []*internal.FragmentSpread) {
+	for _, fragment := range fragments {
+		fragment := fragment
+		ctx.later(func() {
+			ctx := ctx.subsequent()
+			data, err := e.executeObject(ctx, typ, source, fragment.Fragment.SelectionSet)
+			if err != nil {
+				if err != errNullPropagated {
+					ctx.addErr(fragment.Loc, err)
+				}
+				ctx.dropPending(ctx.path)
+				data = nil
+			}
+			ctx.deliver(&IncrementalResult{Data: data, Path: ctx.path, Label: fragment.Defer.Label})
+		})
+	}
+}
+
+// streamItems executes the items of a streamed list left out of the current payload,
+// and delivers them one by one.
+func (e *Executor) streamItems(ctx *exeContext, typ *internal.List, items reflect.Value, selectionSet *internal.SelectionSet) {
+	stream := ctx.stream
+	ctx.later(func() {
+		for i := 0; i < items.Len(); i++ {
+			ctx := ctx.subsequent().withPath(stream.InitialCount + i)
+			item, err := e.execute(ctx, typ.Type, items.Index(i).Interface(), selectionSet)
+			if err != nil {
+				// a non-null item can't be nulled, the error is all there is to deliver
+				ctx.nullify(typ.Type, err)
+				item = nil
+			}
+			ctx.deliver(&IncrementalResult{Items: []interface{}{item}, Path: ctx.path, Label: stream.Label})
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package execution
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// OrderedMap is the result of executing an object. It keeps its fields in
+// selection order and marshals them to JSON in that order, as the spec requires.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap returns an empty OrderedMap with room for size fields.
+func NewOrderedMap(size int) *OrderedMap {
+	return &OrderedMap{
+		keys:   make([]string, 0, size),
+		values: make(map[string]interface{}, size),
+	}
+}
+
+// Set sets the value of key, a new key is placed after every existing one.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of key, and whether it is present.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Keys returns the keys in order.
+func (m *OrderedMap) Keys() []string {
+	return m.keys
+}
+
+// Len returns the number of keys.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Map returns the fields as a plain map, recursively, for callers which don't care about the order.
+func (m *OrderedMap) Map() map[string]interface{} {
+	res := make(map[string]interface{}, len(m.keys))
+	for _, key := range m.keys {
+		res[key] = plain(m.values[key])
+	}
+	return res
+}
+
+func plain(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *OrderedMap:
+		return value.Map()
+	case []interface{}:
+		res := make([]interface{}, len(value))
+		for i, v := range value {
+			res[i] = plain(v)
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -18,8 +18,27 @@ func printErr(loc errors.Location, rule string, format string, a ...interface{})
 	}
 }
 
+// Limits bounds the size of the operations ApplySelectionSetWithLimits accepts, so that a
+// hostile query can't fan out without bound. A zero limit is not enforced.
+type Limits struct {
+	// MaxDepth is the maximum nesting of fields, fragments excluded.
+	MaxDepth int
+	// MaxFields is the maximum number of fields of an operation, once every fragment is expanded.
+	MaxFields int
+	// MaxAliases is the maximum number of aliased fields in a selection set.
+	MaxAliases int
+	// MaxDirectives is the maximum number of directives on a field.
+	MaxDirectives int
+}
+
 func ApplySelectionSet(schema *internal.Schema, document *internal.Document, operationName string, vars map[string]interface{}) (
 	ast.OperationType, *internal.SelectionSet, error) {
+	return ApplySelectionSetWithLimits(schema, document, operationName, vars, Limits{})
+}
+
+// ApplySelectionSetWithLimits is like ApplySelectionSet, but rejects operations over limits.
+func ApplySelectionSetWithLimits(schema *internal.Schema, document *internal.Document, operationName string, vars map[string]interface{},
+	limits Limits) (ast.OperationType, *internal.SelectionSet, error) {
 
 	if document == nil {
 		return "", nil, errors.New("must provide document")
@@ -51,9 +70,9 @@ func ApplySelectionSet(schema *internal.Schema, document *internal.Document, ope
 	}
 	var opName string
 	if op.Name != nil {
-		opName = opName
+		opName = op.Name.Name
 	}
-	if op.Operation == "subscription" && len(op.SelectionSet.Selections) != 1 {
+	if op.Operation == ast.Subscription && len(op.SelectionSet.Selections) != 1 {
 		if opName != "" {
 			return "", nil, printErr(op.Loc, "Single root field", `Subscription "%s" must select only one top level field.`, opName)
 		} else {
@@ -61,6 +80,10 @@ func ApplySelectionSet(schema *internal.Schema, document *internal.Document, ope
 		}
 	}
 
+	if err := checkLimits(op, document.Fragments, limits); err != nil {
+		return "", nil, err
+	}
+
 	var obj *internal.Object
 	switch op.Operation {
 	case ast.Query:
@@ -231,10 +254,17 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 				return nil, err
 			}
 
-			directives, err := parseDirectives(schema, "FIELD", selection.Directives, vars)
+			directives, _, stream, err := parseDirectives(schema, "FIELD", selection.Directives, vars)
 			if err != nil {
 				return nil, err
 			}
+			listType := f.Type
+			if nonNull, ok := listType.(*internal.NonNull); ok {
+				listType = nonNull.Type
+			}
+			if _, ok := listType.(*internal.List); stream != nil && !ok {
+				return nil, printErr(selection.Loc, "StreamOnListField", "Directive \"stream\" may not be used on field %q of non list type %q.", selection.Name.Name, f.Type.String())
+			}
 
 			sf := hasSubfields(f.Type)
 			if sf && (selection.SelectionSet == nil) {
@@ -262,6 +292,7 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 				Args:         args,
 				SelectionSet: selectionSet,
 				Directives:   directives,
+				Stream:       stream,
 				Loc:          selection.Loc,
 			})
 
@@ -273,7 +304,7 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 				return nil, errors.New("unknown fragment")
 			}
 
-			directives, err := parseDirectives(schema, "FRAGMENT_SPREAD", selection.Directives, vars)
+			directives, deferred, _, err := parseDirectives(schema, "FRAGMENT_SPREAD", selection.Directives, vars)
 			if err != nil {
 				return nil, err
 			}
@@ -281,6 +312,7 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 			fragmentSpread := &internal.FragmentSpread{
 				Fragment:   fragment,
 				Directives: directives,
+				Defer:      deferred,
 				Loc:        fragment.Loc,
 			}
 
@@ -292,7 +324,7 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 				on = selection.TypeCondition.Name.Name
 			}
 
-			directives, err := parseDirectives(schema, "INLINE_FRAGMENT", selection.Directives, vars)
+			directives, deferred, _, err := parseDirectives(schema, "INLINE_FRAGMENT", selection.Directives, vars)
 			if err != nil {
 				return nil, err
 			}
@@ -309,12 +341,14 @@ func parseSelectionSet(schema *internal.Schema, t internal.NamedType, input *ast
 					Loc:          selection.Loc,
 				},
 				Directives: directives,
+				Defer:      deferred,
 				Loc:        selection.Loc,
 			})
 		}
 	}
 
 	selectionSet := &internal.SelectionSet{
+		Loc:        input.Loc,
 		Selections: selections,
 		Fragments:  fragments,
 	}
@@ -346,21 +380,157 @@ const (
 	visited
 )
 
-func parseDirectives(schema *internal.Schema, loc string, directives []*ast.Directive, vars map[string]interface{}) ([]*internal.Directive, error) {
+// parseDirectives binds the arguments of directives. @defer and @stream are not returned
+// with the other directives, as they change how the executor delivers a selection instead of
+// resolving it, they are returned on their own when enabled.
+func parseDirectives(schema *internal.Schema, loc string, directives []*ast.Directive, vars map[string]interface{}) (
+	d []*internal.Directive, deferred *internal.Defer, stream *internal.Stream, err error) {
 	if err := validateDirectives(schema, loc, directives); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	d := make([]*internal.Directive, 0, len(directives))
+	d = make([]*internal.Directive, 0, len(directives))
 	for _, directive := range directives {
 		args, err := argsToJson(directive.Args, vars)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
-		dir := schema.Directives[directive.Name.Name]
+		switch directive.Name.Name {
+		case "defer", "stream":
+			if enabled, ok := args["if"].(bool); ok && !enabled {
+				continue
+			}
+			label, _ := args["label"].(string)
+			if directive.Name.Name == "defer" {
+				deferred = &internal.Defer{Label: label}
+				continue
+			}
+			stream = &internal.Stream{Label: label}
+			if initialCount, ok := args["initialCount"].(float64); ok {
+				if initialCount < 0 {
+					return nil, nil, nil, printErr(directive.Loc, "StreamInitialCount", "Argument \"initialCount\" of directive \"stream\" must not be negative.")
+				}
+				stream.InitialCount = int(initialCount)
+			}
+			continue
+		}
+		// copy the directive, the schema's one is shared by every query
+		dir := *schema.Directives[directive.Name.Name]
 		dir.ArgVals = args
-		d = append(d, dir)
+		d = append(d, &dir)
+	}
+	return d, deferred, stream, nil
+}
+
+// CheckLimits checks the operation of document named operationName against limits. It only walks
+// the document, which needs not be validated, so that hostile operations are rejected before the work
+// of validating them and building their selection sets. Operations which can't be found pass.
+func CheckLimits(document *internal.Document, operationName string, limits Limits) error {
+	if limits == (Limits{}) || document == nil {
+		return nil
+	}
+	var op *ast.OperationDefinition
+	if operationName == "" {
+		if len(document.Operations) != 1 {
+			return nil
+		}
+		op = document.Operations[0]
+	} else {
+		for _, o := range document.Operations {
+			if o.Name != nil && o.Name.Name == operationName {
+				op = o
+			}
+		}
+		if op == nil {
+			return nil
+		}
 	}
-	return d, nil
+	return checkLimits(op, document.Fragments, limits)
+}
+
+// checkLimits walks the selection set of op. The depth and field count of every selection set
+// is memoized, fragments spread many times are only walked once. Fragments which are unknown or
+// spread within themselves are left for validation to report.
+func checkLimits(op *ast.OperationDefinition, fragments []*ast.FragmentDefinition, limits Limits) error {
+	if limits == (Limits{}) {
+		return nil
+	}
+	fragmentSets := make(map[string]*ast.SelectionSet)
+	for _, fragment := range fragments {
+		fragmentSets[fragment.Name.Name] = fragment.SelectionSet
+	}
+	type size struct {
+		depth  int
+		fields int
+		// deepest is the location of the deepest field
+		deepest errors.Location
+	}
+	sizes := make(map[*ast.SelectionSet]size)
+	visiting := make(map[*ast.SelectionSet]bool)
+
+	var visit func(*ast.SelectionSet) (size, error)
+	visit = func(selectionSet *ast.SelectionSet) (size, error) {
+		if selectionSet == nil || visiting[selectionSet] {
+			return size{}, nil
+		}
+		if s, ok := sizes[selectionSet]; ok {
+			return s, nil
+		}
+		visiting[selectionSet] = true
+		defer delete(visiting, selectionSet)
+
+		var res size
+		var aliases int
+		for _, selection := range selectionSet.Selections {
+			var fragment *ast.SelectionSet
+			switch selection := selection.(type) {
+			case *ast.Field:
+				if selection.Alias != nil && selection.Alias.Name != selection.Name.Name {
+					aliases++
+					if limits.MaxAliases > 0 && aliases > limits.MaxAliases {
+						return res, printErr(selection.Loc, "MaxAliases", "Selection set must not have more than %d aliases.", limits.MaxAliases)
+					}
+				}
+				if limits.MaxDirectives > 0 && len(selection.Directives) > limits.MaxDirectives {
+					return res, printErr(selection.Loc, "MaxDirectives", "Field %q must not have more than %d directives.", selection.Alias.Name, limits.MaxDirectives)
+				}
+				child, err := visit(selection.SelectionSet)
+				if err != nil {
+					return res, err
+				}
+				if child.depth+1 > res.depth {
+					res.depth, res.deepest = child.depth+1, selection.Loc
+					if child.depth > 0 {
+						res.deepest = child.deepest
+					}
+				}
+				res.fields += child.fields + 1
+				continue
+			case *ast.FragmentSpread:
+				fragment = fragmentSets[selection.Name.Name]
+			case *ast.InlineFragment:
+				fragment = selection.SelectionSet
+			}
+			child, err := visit(fragment)
+			if err != nil {
+				return res, err
+			}
+			if child.depth > res.depth {
+				res.depth, res.deepest = child.depth, child.deepest
+			}
+			res.fields += child.fields
+		}
+		if limits.MaxDepth > 0 && res.depth > limits.MaxDepth {
+			return res, printErr(res.deepest, "MaxDepth", "Field nesting must not be deeper than %d.", limits.MaxDepth)
+		}
+		if limits.MaxFields > 0 && res.fields > limits.MaxFields {
+			return res, printErr(op.Loc, "MaxFields", "Operation must not select more than %d fields.", limits.MaxFields)
+		}
+		sizes[selectionSet] = res
+		return res, nil
+	}
+
+	_, err := visit(op.SelectionSet)
+	return err
 }
 
 // detectCyclesAndUnusedFragments finds cycles in fragments that include eachother as well as fragments that don't appear anywhere
@@ -494,7 +664,17 @@ func detectConflicts(selectionSet *internal.SelectionSet) error {
 // Flatten does _not_ flatten out the inner queries, so the name above does not
 // get flattened out yet.
 func Flatten(selectionSet *internal.SelectionSet) ([]*internal.Selection, error) {
+	selections, _, err := flatten(selectionSet, false)
+	return selections, err
+}
+
+// flatten is Flatten, except that when deferring, the fragments marked with @defer
+// are returned apart instead of being merged in the selections.
+func flatten(selectionSet *internal.SelectionSet, deferring bool) ([]*internal.Selection, []*internal.FragmentSpread, error) {
+	var deferred []*internal.FragmentSpread
 	grouped := make(map[string][]*internal.Selection)
+	// aliases keeps the order in which aliases first appear, mutations rely on it
+	var aliases []string
 
 	state := make(map[*internal.SelectionSet]visitState)
 	var visit func(*internal.SelectionSet) error
@@ -504,6 +684,9 @@ func Flatten(selectionSet *internal.SelectionSet) ([]*internal.Selection, error)
 		}
 
 		for _, selection := range selectionSet.Selections {
+			if _, ok := grouped[selection.Alias]; !ok {
+				aliases = append(aliases, selection.Alias)
+			}
 			grouped[selection.Alias] = append(grouped[selection.Alias], selection)
 		}
 		for _, fragment := range selectionSet.Fragments {
@@ -514,6 +697,10 @@ func Flatten(selectionSet *internal.SelectionSet) ([]*internal.Selection, error)
 				continue
 
 			}
+			if deferring && fragment.Defer != nil {
+				deferred = append(deferred, fragment)
+				continue
+			}
 			if err := visit(fragment.Fragment.SelectionSet); err != nil {
 				return err
 			}
@@ -524,11 +711,12 @@ func Flatten(selectionSet *internal.SelectionSet) ([]*internal.Selection, error)
 	}
 
 	if err := visit(selectionSet); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var flattened []*internal.Selection
-	for _, selections := range grouped {
+	for _, alias := range aliases {
+		selections := grouped[alias]
 		if len(selections) == 1 || selections[0].SelectionSet == nil {
 			flattened = append(flattened, selections[0])
 			continue
@@ -545,11 +733,12 @@ func Flatten(selectionSet *internal.SelectionSet) ([]*internal.Selection, error)
 			Alias:        selections[0].Alias,
 			Args:         selections[0].Args,
 			SelectionSet: merged,
+			Stream:       selections[0].Stream,
 			Loc:          selections[0].Loc,
 		})
 	}
 
-	return flattened, nil
+	return flattened, deferred, nil
 }
 
 func validateValue(v *ast.VariableDefinition, val interface{}, vtyp internal.Type, names ...string) error {
@@ -0,0 +1,82 @@
+package execution_test
+
+import (
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplySelectionSetWithLimits(t *testing.T) {
+	type Node struct {
+		ID int `graphql:"id"`
+	}
+	build := schemabuilder.NewSchema()
+	build.Object("Node", Node{}).FieldFunc("next", func() *Node { return nil }, "")
+	build.Query().FieldFunc("node", func() *Node { return nil }, "")
+	schema := build.MustBuild()
+
+	apply := func(query string, limits execution.Limits) error {
+		doc, err := internal.Parse(query)
+		assert.NoError(t, err)
+		_, _, err = execution.ApplySelectionSetWithLimits(schema, doc, "", map[string]interface{}{}, limits)
+		return err
+	}
+	assertErr := func(err error, message, rule string, loc errors.Location) {
+		if assert.IsType(t, &errors.GraphQLError{}, err) {
+			assert.Equal(t, message, err.(*errors.GraphQLError).Message)
+			assert.Equal(t, rule, err.(*errors.GraphQLError).Rule)
+			assert.Equal(t, []errors.Location{loc}, err.(*errors.GraphQLError).Locations)
+		}
+	}
+
+	t.Run("depth", func(t *testing.T) {
+		limits := execution.Limits{MaxDepth: 3}
+		assert.NoError(t, apply("{ node { next { id } } }", limits))
+		assertErr(apply("{ node { next { next { id } } } }", limits),
+			"Field nesting must not be deeper than 3.", "MaxDepth", errors.Location{Line: 1, Column: 24})
+		// fragments are expanded where they are spread
+		assertErr(apply("{ node { ...F } } fragment F on Node { next { next { id } } }", limits),
+			"Field nesting must not be deeper than 3.", "MaxDepth", errors.Location{Line: 1, Column: 54})
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		limits := execution.Limits{MaxFields: 6}
+		assert.NoError(t, apply("{ node { id ...F } } fragment F on Node { next { id } b: next { id } }", limits))
+		assertErr(apply("{ node { id ...F } other: node { ...F } } fragment F on Node { next { id } }", limits),
+			"Operation must not select more than 6 fields.", "MaxFields", errors.Location{Line: 1, Column: 1})
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		limits := execution.Limits{MaxAliases: 1}
+		assert.NoError(t, apply("{ a: node { id } node { b: id } }", limits))
+		assertErr(apply("{ a: node { id } b: node { id } }", limits),
+			"Selection set must not have more than 1 aliases.", "MaxAliases", errors.Location{Line: 1, Column: 26})
+	})
+
+	t.Run("directives", func(t *testing.T) {
+		limits := execution.Limits{MaxDirectives: 1}
+		assert.NoError(t, apply("{ node { id @include(if: true) } }", limits))
+		assertErr(apply("{ node { id @include(if: true) @skip(if: false) } }", limits),
+			`Field "id" must not have more than 1 directives.`, "MaxDirectives", errors.Location{Line: 1, Column: 10})
+	})
+}
+
+func TestCheckLimits(t *testing.T) {
+	check := func(query, operationName string, limits execution.Limits) error {
+		doc, err := internal.Parse(query)
+		assert.NoError(t, err)
+		return execution.CheckLimits(doc, operationName, limits)
+	}
+
+	// the document needs not be valid, unknown fields and fragments are left to validation
+	err := check("{ a { b { c { d } } } ...Unknown }", "", execution.Limits{MaxDepth: 3})
+	if assert.IsType(t, &errors.GraphQLError{}, err) {
+		assert.Equal(t, "MaxDepth", err.(*errors.GraphQLError).Rule)
+	}
+	assert.NoError(t, check("query A { a { b } } query B { a { b { c { d } } } }", "A", execution.Limits{MaxDepth: 3}))
+	assert.Error(t, check("query A { a { b } } query B { a { b { c { d } } } }", "B", execution.Limits{MaxDepth: 3}))
+	assert.NoError(t, check("{ ...F } fragment F on Query { ...F }", "", execution.Limits{MaxFields: 1}))
+}
@@ -0,0 +1,109 @@
+package execution
+
+import (
+	"context"
+	"github.com/shyptr/graphql/internal"
+	"sync"
+	"time"
+)
+
+// Tracing records the timings of one request in the Apollo tracing format,
+// see https://github.com/apollographql/apollo-tracing.
+type Tracing struct {
+	Version    int              `json:"version"`
+	StartTime  time.Time        `json:"startTime"`
+	EndTime    time.Time        `json:"endTime"`
+	Duration   time.Duration    `json:"duration"`
+	Parsing    TracingPhase     `json:"parsing"`
+	Validation TracingPhase     `json:"validation"`
+	Execution  TracingExecution `json:"execution"`
+
+	mu sync.Mutex
+}
+
+// TracingPhase is the timing of a phase, its offset is relative to the start of the request.
+type TracingPhase struct {
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+}
+
+type TracingExecution struct {
+	Resolvers []*ResolverTrace `json:"resolvers"`
+}
+
+// ResolverTrace is the timing of one call of a resolver.
+type ResolverTrace struct {
+	Path        []interface{} `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	ReturnType  string        `json:"returnType"`
+	StartOffset time.Duration `json:"startOffset"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// NewTracing starts tracing a request.
+func NewTracing() *Tracing {
+	return &Tracing{
+		Version:   1,
+		StartTime: time.Now(),
+		Execution: TracingExecution{Resolvers: []*ResolverTrace{}},
+	}
+}
+
+// TraceParsing records that parsing started at start and just ended.
+func (t *Tracing) TraceParsing(start time.Time) {
+	if t != nil {
+		t.Parsing = t.phase(start)
+	}
+}
+
+// TraceValidation records that validation started at start and just ended.
+func (t *Tracing) TraceValidation(start time.Time) {
+	if t != nil {
+		t.Validation = t.phase(start)
+	}
+}
+
+// Finish records the end of the request.
+func (t *Tracing) Finish() {
+	t.EndTime = time.Now()
+	t.Duration = t.EndTime.Sub(t.StartTime)
+}
+
+func (t *Tracing) phase(start time.Time) TracingPhase {
+	return TracingPhase{StartOffset: start.Sub(t.StartTime), Duration: time.Since(start)}
+}
+
+func (t *Tracing) addResolver(trace *ResolverTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Execution.Resolvers = append(t.Execution.Resolvers, trace)
+}
+
+type tracingKey struct{}
+
+// WithTracing makes the executions run with ctx record their resolvers in t.
+// A ctx which can store values itself, like graphql.Context, is returned as is.
+func WithTracing(ctx context.Context, t *Tracing) context.Context {
+	if setter, ok := ctx.(interface{ Set(key, value interface{}) }); ok {
+		setter.Set(tracingKey{}, t)
+		return ctx
+	}
+	return context.WithValue(ctx, tracingKey{}, t)
+}
+
+// trace records the call of the resolver of field at the current path.
+// The elements of a list share the timing of the batch resolver called for all of them.
+func (e *exeContext) trace(parentType string, field *internal.Field, start, end time.Time) {
+	if e.tracing == nil {
+		return
+	}
+	e.tracing.addResolver(&ResolverTrace{
+		Path:        e.path,
+		ParentType:  parentType,
+		FieldName:   field.Name,
+		ReturnType:  field.Type.String(),
+		StartOffset: start.Sub(e.tracing.StartTime),
+		Duration:    end.Sub(start),
+	})
+}
@@ -0,0 +1,408 @@
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"sort"
+	"strings"
+)
+
+// ChangeLevel tells how a change between two versions of a schema affects the clients of the old one.
+type ChangeLevel string
+
+const (
+	// Breaking changes make queries of the old schema fail, like removing a field or making
+	// an argument non-null.
+	Breaking ChangeLevel = "breaking"
+
+	// Dangerous changes keep queries valid, but clients may get values they don't expect,
+	// like a new enum value or a new union member.
+	Dangerous ChangeLevel = "dangerous"
+
+	// Safe changes don't affect existing queries, like a new field.
+	Safe ChangeLevel = "safe"
+)
+
+// Change is a change between two versions of a schema.
+type Change struct {
+	Level ChangeLevel `json:"level"`
+	// Path is the changed type, field, argument or value, like Query.hero(episode:)
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s: %s", c.Level, c.Path, c.Message)
+}
+
+// Changes are the changes between two versions of a schema, in the order of the types they are on.
+type Changes []Change
+
+// Level returns the changes of level.
+func (c Changes) Level(level ChangeLevel) Changes {
+	var changes Changes
+	for _, change := range c {
+		if change.Level == level {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Breaking tells whether any of the changes is breaking.
+func (c Changes) Breaking() bool {
+	return len(c.Level(Breaking)) > 0
+}
+
+// DiffSchemas returns the changes from the old to the new version of a schema. The schemas are compared
+// by their introspection, which is added to copies of the schemas which don't have it yet.
+func DiffSchemas(old, new *internal.Schema) (Changes, error) {
+	oldJSON, err := schemaJSON(old)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %v", err)
+	}
+	newJSON, err := schemaJSON(new)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %v", err)
+	}
+	return DiffSchemaJSON(oldJSON, newJSON)
+}
+
+func schemaJSON(schema *internal.Schema) ([]byte, error) {
+	if query, ok := schema.Query.(*internal.Object); ok && query.Fields["__schema"] == nil {
+		// the introspection replaces the query and adds types, which are left as they were in schema
+		copied := *schema
+		copied.TypeMap = make(map[string]internal.NamedType, len(schema.TypeMap))
+		for name, typ := range schema.TypeMap {
+			copied.TypeMap[name] = typ
+		}
+		introspection.AddIntrospectionToSchema(&copied)
+		schema = &copied
+	}
+	return introspection.ComputeSchemaJSON(schema)
+}
+
+// DiffSchemaJSON returns the changes from the old to the new version of a schema, given as the results
+// of the introspection query, like those of introspection.ComputeSchemaJSON. Whole responses, with the
+// result under "data", are accepted as well.
+func DiffSchemaJSON(old, new []byte) (Changes, error) {
+	oldSchema, err := parseIntrospection(old)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %v", err)
+	}
+	newSchema, err := parseIntrospection(new)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %v", err)
+	}
+	var d differ
+	d.diffSchemas(&oldSchema.Schema, &newSchema.Schema)
+	return d.changes, nil
+}
+
+func parseIntrospection(schemaJSON []byte) (*introspectionQueryResult, error) {
+	var result struct {
+		introspectionQueryResult
+		Data *introspectionQueryResult `json:"data"`
+	}
+	if err := json.Unmarshal(schemaJSON, &result); err != nil {
+		return nil, err
+	}
+	if result.Data != nil {
+		return result.Data, nil
+	}
+	if result.Schema.Types == nil {
+		return nil, fmt.Errorf("not the result of an introspection query")
+	}
+	return &result.introspectionQueryResult, nil
+}
+
+// differ collects the changes between two schemas. The elements are compared by name,
+// in order, so that the same schemas always give the same changes.
+type differ struct {
+	changes Changes
+}
+
+func (d *differ) add(level ChangeLevel, path, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{Level: level, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// pair indexes the elements of the old and new versions of a list by name, and returns their names in order.
+func pair(oldLen, newLen int, name func(i int, new bool) string) (names []string, oldIndex, newIndex map[string]int) {
+	oldIndex, newIndex = make(map[string]int, oldLen), make(map[string]int, newLen)
+	for i := 0; i < oldLen; i++ {
+		oldIndex[name(i, false)] = i
+		names = append(names, name(i, false))
+	}
+	for i := 0; i < newLen; i++ {
+		if _, ok := oldIndex[name(i, true)]; !ok {
+			names = append(names, name(i, true))
+		}
+		newIndex[name(i, true)] = i
+	}
+	sort.Strings(names)
+	return names, oldIndex, newIndex
+}
+
+// specifiedScalars are the built-in scalars, which every schema has.
+var specifiedScalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+func (d *differ) diffSchemas(old, new *introspectionSchema) {
+	for _, root := range []struct {
+		operation string
+		old, new  *introspectionTypeRef
+	}{
+		{"query", old.QueryType, new.QueryType},
+		{"mutation", old.MutationType, new.MutationType},
+		{"subscription", old.SubscriptionType, new.SubscriptionType},
+	} {
+		switch {
+		case root.old == nil && root.new != nil:
+			d.add(Safe, root.new.Name, "%s type added", root.operation)
+		case root.old != nil && root.new == nil:
+			d.add(Breaking, root.old.Name, "%s type removed", root.operation)
+		case root.old != nil && root.old.Name != root.new.Name:
+			d.add(Breaking, root.new.Name, "%s type changed from %s", root.operation, root.old.Name)
+		}
+	}
+
+	names, oldIndex, newIndex := pair(len(old.Types), len(new.Types), func(i int, isNew bool) string {
+		if isNew {
+			return new.Types[i].Name
+		}
+		return old.Types[i].Name
+	})
+	for _, name := range names {
+		// introspection only lists the built-in scalars which are used
+		if strings.HasPrefix(name, "__") || specifiedScalars[name] {
+			continue
+		}
+		o, inOld := oldIndex[name]
+		n, inNew := newIndex[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, name, "type removed")
+		case !inOld:
+			d.add(Safe, name, "type added")
+		default:
+			d.diffTypes(&old.Types[o], &new.Types[n])
+		}
+	}
+
+	names, oldIndex, newIndex = pair(len(old.Directives), len(new.Directives), func(i int, isNew bool) string {
+		if isNew {
+			return new.Directives[i].Name
+		}
+		return old.Directives[i].Name
+	})
+	for _, name := range names {
+		o, inOld := oldIndex[name]
+		n, inNew := newIndex[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, "@"+name, "directive removed")
+		case !inOld:
+			d.add(Safe, "@"+name, "directive added")
+		default:
+			d.diffDirectives(&old.Directives[o], &new.Directives[n])
+		}
+	}
+}
+
+func (d *differ) diffTypes(old, new *introspectionType) {
+	if old.Kind != new.Kind {
+		d.add(Breaking, old.Name, "kind changed from %s to %s", old.Kind, new.Kind)
+		return
+	}
+	d.diffDescriptions(old.Name, old.Desc, new.Desc)
+	switch old.Kind {
+	case "OBJECT", "INTERFACE":
+		d.diffFields(old.Name, old.Fields, new.Fields)
+		d.diffMembers(old.Name, "interface", old.Interfaces, new.Interfaces)
+	case "UNION":
+		d.diffMembers(old.Name, "member", old.PossibleTypes, new.PossibleTypes)
+	case "INPUT_OBJECT":
+		d.diffInputValues(old.Name, "input field", old.InputFields, new.InputFields)
+	case "ENUM":
+		d.diffEnumValues(old.Name, old.EnumValues, new.EnumValues)
+	}
+}
+
+func (d *differ) diffDescriptions(path, old, new string) {
+	if old != new {
+		d.add(Safe, path, "description changed")
+	}
+}
+
+func (d *differ) diffDeprecations(path string, wasDeprecated, isDeprecated bool, reason string) {
+	switch {
+	case !wasDeprecated && isDeprecated:
+		d.add(Safe, path, "deprecated: %s", reason)
+	case wasDeprecated && !isDeprecated:
+		d.add(Safe, path, "no longer deprecated")
+	}
+}
+
+func (d *differ) diffFields(typeName string, old, new []introspectionField) {
+	names, oldIndex, newIndex := pair(len(old), len(new), func(i int, isNew bool) string {
+		if isNew {
+			return new[i].Name
+		}
+		return old[i].Name
+	})
+	for _, name := range names {
+		path := typeName + "." + name
+		o, inOld := oldIndex[name]
+		n, inNew := newIndex[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "field removed")
+			continue
+		case !inOld:
+			d.add(Safe, path, "field added")
+			continue
+		}
+		oldField, newField := &old[o], &new[n]
+		switch {
+		case !safeOutputChange(oldField.Type, newField.Type):
+			d.add(Breaking, path, "type changed from %s to %s", oldField.Type, newField.Type)
+		case oldField.Type.String() != newField.Type.String():
+			d.add(Safe, path, "type changed from %s to %s", oldField.Type, newField.Type)
+		}
+		d.diffDescriptions(path, oldField.Desc, newField.Desc)
+		d.diffDeprecations(path, oldField.IsDeprecated, newField.IsDeprecated, newField.DeprecationReason)
+		d.diffInputValues(path, "argument", oldField.Args, newField.Args)
+	}
+}
+
+// diffInputValues compares the arguments of a field or a directive, or the fields of an input object.
+func (d *differ) diffInputValues(owner, kind string, old, new []introspectionInputField) {
+	names, oldIndex, newIndex := pair(len(old), len(new), func(i int, isNew bool) string {
+		if isNew {
+			return new[i].Name
+		}
+		return old[i].Name
+	})
+	for _, name := range names {
+		path := owner + "." + name
+		if kind == "argument" {
+			path = owner + "(" + name + ":)"
+		}
+		o, inOld := oldIndex[name]
+		n, inNew := newIndex[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "%s removed", kind)
+			continue
+		case !inOld:
+			// queries which don't give a required value fail
+			if new[n].Type.Kind == "NON_NULL" && new[n].DefaultValue == "" {
+				d.add(Breaking, path, "required %s added", kind)
+			} else {
+				d.add(Dangerous, path, "optional %s added", kind)
+			}
+			continue
+		}
+		oldValue, newValue := &old[o], &new[n]
+		switch {
+		case !safeInputChange(oldValue.Type, newValue.Type):
+			d.add(Breaking, path, "type changed from %s to %s", oldValue.Type, newValue.Type)
+		case oldValue.Type.String() != newValue.Type.String():
+			d.add(Safe, path, "type changed from %s to %s", oldValue.Type, newValue.Type)
+		}
+		if oldValue.DefaultValue != newValue.DefaultValue {
+			d.add(Dangerous, path, "default value changed from %q to %q", oldValue.DefaultValue, newValue.DefaultValue)
+		}
+		d.diffDescriptions(path, oldValue.Desc, newValue.Desc)
+		d.diffDeprecations(path, oldValue.IsDeprecated, newValue.IsDeprecated, newValue.DeprecationReason)
+	}
+}
+
+// diffMembers compares the interfaces of an object or an interface, or the members of a union.
+func (d *differ) diffMembers(typeName, kind string, old, new []*introspectionTypeRef) {
+	names, oldIndex, newIndex := pair(len(old), len(new), func(i int, isNew bool) string {
+		if isNew {
+			return new[i].Name
+		}
+		return old[i].Name
+	})
+	for _, name := range names {
+		_, inOld := oldIndex[name]
+		_, inNew := newIndex[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, typeName, "%s %s removed", kind, name)
+		case !inOld:
+			// clients may not expect values of the new type
+			d.add(Dangerous, typeName, "%s %s added", kind, name)
+		}
+	}
+}
+
+func (d *differ) diffEnumValues(typeName string, old, new []introspectionEnumValue) {
+	names, oldIndex, newIndex := pair(len(old), len(new), func(i int, isNew bool) string {
+		if isNew {
+			return new[i].Name
+		}
+		return old[i].Name
+	})
+	for _, name := range names {
+		path := typeName + "." + name
+		o, inOld := oldIndex[name]
+		n, inNew := newIndex[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "enum value removed")
+		case !inOld:
+			// clients may not expect the new value
+			d.add(Dangerous, path, "enum value added")
+		default:
+			d.diffDescriptions(path, old[o].Desc, new[n].Desc)
+			d.diffDeprecations(path, old[o].IsDeprecated, new[n].IsDeprecated, new[n].DeprecationReason)
+		}
+	}
+}
+
+func (d *differ) diffDirectives(old, new *introspectionDirective) {
+	path := "@" + old.Name
+	d.diffDescriptions(path, old.Desc, new.Desc)
+	locations := make(map[string]bool, len(new.Locations))
+	for _, location := range new.Locations {
+		locations[location] = true
+	}
+	for _, location := range old.Locations {
+		if !locations[location] {
+			d.add(Breaking, path, "location %s removed", location)
+		}
+		delete(locations, location)
+	}
+	for _, location := range new.Locations {
+		if locations[location] {
+			d.add(Safe, path, "location %s added", location)
+		}
+	}
+	d.diffInputValues(path, "argument", old.Args, new.Args)
+}
+
+// safeOutputChange tells whether a field of type new returns values its clients can read as values of
+// type old: it is the same type, with more non-null modifiers.
+func safeOutputChange(old, new *introspectionTypeRef) bool {
+	if old.Kind == "NON_NULL" {
+		return new.Kind == "NON_NULL" && safeOutputChange(old.OfType, new.OfType)
+	}
+	if new.Kind == "NON_NULL" {
+		return safeOutputChange(old, new.OfType)
+	}
+	if old.Kind == "LIST" {
+		return new.Kind == "LIST" && safeOutputChange(old.OfType, new.OfType)
+	}
+	// a named type changing its kind is reported on the type itself
+	return new.Kind != "LIST" && old.Name == new.Name
+}
+
+// safeInputChange tells whether an input of type new accepts all the values of type old, which is
+// the case when old values can be read as new ones.
+func safeInputChange(old, new *introspectionTypeRef) bool {
+	return safeOutputChange(new, old)
+}
@@ -0,0 +1,189 @@
+package federation_test
+
+import (
+	"github.com/shyptr/graphql/federation"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const oldSDL = `
+enum Color { RED GREEN }
+
+type Item {
+  id: ID!
+  name: String
+  color: Color
+}
+
+input Filter {
+  color: Color
+  limit: Int = 10
+}
+
+union Result = Item
+
+type Query {
+  items(filter: Filter): [Item!]
+  item(id: ID!): Item
+  search(text: String!): [Result]
+}
+`
+
+const newSDL = `
+enum Color { RED GREEN BLUE }
+
+"""An item of the store."""
+type Item {
+  id: ID!
+  name: String!
+  price: Float @deprecated(reason: "not for sale")
+}
+
+type Tag {
+  name: String
+}
+
+input Filter {
+  color: Color!
+  limit: Int = 20 @deprecated
+  offset: Int
+}
+
+union Result = Item | Tag
+
+type Query {
+  items(filter: Filter @deprecated(reason: "use search"), first: Int!): [Item!]
+  item(id: String!): Item
+  search(text: String): [Result]
+  tags: [Tag]
+}
+
+directive @upper on FIELD
+`
+
+type diffItem struct {
+	ID    string  `graphql:"id"`
+	Name  string  `graphql:"name"`
+	Color string  `graphql:"color"`
+	Price float64 `graphql:"price"`
+}
+
+type diffTag struct {
+	Name string `graphql:"name"`
+}
+
+type diffOldFilter struct {
+	Color string `graphql:"color"`
+	Limit int    `graphql:"limit"`
+}
+
+type diffNewFilter struct {
+	Color  string `graphql:"color"`
+	Limit  int    `graphql:"limit"`
+	Offset int    `graphql:"offset"`
+}
+
+type diffItemArgs struct {
+	ID string `graphql:"id"`
+}
+
+type diffSearchArgs struct {
+	Text string `graphql:"text"`
+}
+
+func TestDiffSchemas(t *testing.T) {
+	old := schemabuilder.MustBuildSDL(oldSDL, schemabuilder.Resolvers{
+		"Query.items": func(args struct {
+			Filter *diffOldFilter `graphql:"filter"`
+		}) []diffItem {
+			return nil
+		},
+		"Query.item":   func(args diffItemArgs) *diffItem { return nil },
+		"Query.search": func(args diffSearchArgs) []struct{ Item *diffItem } { return nil },
+	})
+	new := schemabuilder.MustBuildSDL(newSDL, schemabuilder.Resolvers{
+		"Query.items": func(args struct {
+			Filter *diffNewFilter `graphql:"filter"`
+			First  int            `graphql:"first"`
+		}) []diffItem {
+			return nil
+		},
+		"Query.item": func(args diffItemArgs) *diffItem { return nil },
+		"Query.search": func(args diffSearchArgs) []struct {
+			Item *diffItem
+			Tag  *diffTag
+		} {
+			return nil
+		},
+		"Query.tags": func() []diffTag { return nil },
+		"@upper": func(field func() (interface{}, error)) (interface{}, error) {
+			return field()
+		},
+	})
+	introspection.AddIntrospectionToSchema(old)
+	query, types := new.Query, len(new.TypeMap)
+
+	changes, err := federation.DiffSchemas(old, new)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, federation.Changes{
+		{federation.Dangerous, "Color.BLUE", "enum value added"},
+		{federation.Breaking, "Filter.color", "type changed from Color to Color!"},
+		{federation.Dangerous, "Filter.limit", `default value changed from "10" to "20"`},
+		{federation.Safe, "Filter.limit", "deprecated: No longer supported"},
+		{federation.Dangerous, "Filter.offset", "optional input field added"},
+		{federation.Safe, "Item", "description changed"},
+		{federation.Breaking, "Item.color", "field removed"},
+		{federation.Safe, "Item.name", "type changed from String to String!"},
+		{federation.Safe, "Item.price", "field added"},
+		{federation.Breaking, "Query.item(id:)", "type changed from ID! to String!"},
+		{federation.Safe, "Query.items(filter:)", "deprecated: use search"},
+		{federation.Breaking, "Query.items(first:)", "required argument added"},
+		{federation.Safe, "Query.search(text:)", "type changed from String! to String"},
+		{federation.Safe, "Query.tags", "field added"},
+		{federation.Dangerous, "Result", "member Tag added"},
+		{federation.Safe, "Tag", "type added"},
+		{federation.Safe, "@upper", "directive added"},
+	}, changes)
+	assert.True(t, changes.Breaking())
+	assert.Len(t, changes.Level(federation.Dangerous), 4)
+	// the introspection is added to a copy of new
+	assert.Equal(t, query, new.Query)
+	assert.Nil(t, new.Query.(*internal.Object).Fields["__schema"])
+	assert.Len(t, new.TypeMap, types)
+
+	changes, err = federation.DiffSchemas(new, new)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiffSchemaJSON(t *testing.T) {
+	old := `{"data":{"__schema":{"queryType":{"name":"Query"},"types":[
+		{"kind":"OBJECT","name":"Query","fields":[{"name":"hero","args":[],"type":{"kind":"OBJECT","name":"Character"}}],"interfaces":[]},
+		{"kind":"OBJECT","name":"Character","fields":[{"name":"name","args":[],"type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"String"}}}],"interfaces":[]},
+		{"kind":"ENUM","name":"Episode","enumValues":[{"name":"JEDI"}]}
+	],"directives":[]}}}`
+	new := `{"__schema":{"queryType":{"name":"Query"},"mutationType":{"name":"Mutation"},"types":[
+		{"kind":"OBJECT","name":"Query","fields":[{"name":"hero","args":[],"type":{"kind":"OBJECT","name":"Character"}}],"interfaces":[]},
+		{"kind":"OBJECT","name":"Character","fields":[{"name":"name","args":[],"type":{"kind":"SCALAR","name":"String"}}],"interfaces":[]},
+		{"kind":"SCALAR","name":"Episode"},
+		{"kind":"OBJECT","name":"Mutation","fields":[],"interfaces":[]}
+	],"directives":[]}}`
+
+	changes, err := federation.DiffSchemaJSON([]byte(old), []byte(new))
+	if assert.NoError(t, err) {
+		assert.Equal(t, federation.Changes{
+			{federation.Safe, "Mutation", "mutation type added"},
+			{federation.Breaking, "Character.name", "type changed from String! to String"},
+			{federation.Breaking, "Episode", "kind changed from ENUM to SCALAR"},
+			{federation.Safe, "Mutation", "type added"},
+		}, changes)
+	}
+
+	_, err = federation.DiffSchemaJSON([]byte(old), []byte(`{"data":null}`))
+	assert.EqualError(t, err, "new schema: not the result of an introspection query")
+}
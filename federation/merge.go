@@ -55,7 +55,7 @@ func (t *introspectionTypeRef) String() string {
 		return "<nil>"
 	}
 	switch t.Kind {
-	case "SCALAR", "ENUM", "UNION", "OBJECT", "INPUT_OBJECT":
+	case "SCALAR", "ENUM", "UNION", "OBJECT", "INTERFACE", "INPUT_OBJECT":
 		return t.Name
 	case "NON_NULL":
 		return t.OfType.String() + "!"
@@ -67,10 +67,12 @@ func (t *introspectionTypeRef) String() string {
 }
 
 type introspectionInputField struct {
-	Name         string                `json:"name"`
-	Desc         string                `json:"description"`
-	DefaultValue string                `json:"defaultValue"`
-	Type         *introspectionTypeRef `json:"type"`
+	Name              string                `json:"name"`
+	Desc              string                `json:"description"`
+	DefaultValue      string                `json:"defaultValue"`
+	Type              *introspectionTypeRef `json:"type"`
+	DeprecationReason string                `json:"deprecationReason"`
+	IsDeprecated      bool                  `json:"isDeprecated"`
 }
 
 type introspectionField struct {
@@ -100,8 +102,20 @@ type introspectionType struct {
 	Interfaces    []*introspectionTypeRef   `json:"interfaces"`
 }
 
+type introspectionDirective struct {
+	Name         string                    `json:"name"`
+	Desc         string                    `json:"description"`
+	Locations    []string                  `json:"locations"`
+	Args         []introspectionInputField `json:"args"`
+	IsRepeatable bool                      `json:"isRepeatable"`
+}
+
 type introspectionSchema struct {
-	Types []introspectionType `json:"types"`
+	QueryType        *introspectionTypeRef    `json:"queryType"`
+	MutationType     *introspectionTypeRef    `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef    `json:"subscriptionType"`
+	Types            []introspectionType      `json:"types"`
+	Directives       []introspectionDirective `json:"directives"`
 }
 
 type introspectionQueryResult struct {
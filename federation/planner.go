@@ -6,6 +6,7 @@ import (
 	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/execution"
 	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/validation"
 	"sort"
 )
 
@@ -458,10 +459,9 @@ func MustPlan(planner *Planner, param execution.Params) (*Plan, error) {
 	if err != nil {
 		return nil, err
 	}
-	//errs := validation.Validate(planner.schema.Schema, doc, param.Variables, 50)
-	//if len(errs) > 0 {
-	//	return nil, errs
-	//}
+	if errs := validation.Validate(planner.schema.Schema, doc); len(errs) > 0 {
+		return nil, errs
+	}
 	operationType, selectionSet, err := execution.ApplySelectionSet(planner.schema.Schema, doc, param.OperationName, param.Variables)
 	if err != nil {
 		return nil, err
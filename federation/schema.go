@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/internal"
 	"sort"
 )
@@ -208,15 +209,51 @@ func parseInputFields(source []introspectionInputField, all map[string]internal.
 			return nil, fmt.Errorf("field %s has bad typ: %v", field.Name, err)
 		}
 		fields[field.Name] = &internal.InputField{
-			Name: field.Name,
-			Type: inputType,
-			Desc: field.Name,
+			Name:              field.Name,
+			Type:              inputType,
+			Desc:              field.Desc,
+			DefaultValue:      parseDefaultValue(field.DefaultValue, inputType),
+			DeprecationReason: deprecationReason(field.IsDeprecated, field.DeprecationReason),
 		}
 	}
 
 	return fields, nil
 }
 
+// parseDefaultValue maps an introspected default value to the value of a default of typ. Strings
+// may be given unquoted, and values which aren't GraphQL literals are kept as they are.
+func parseDefaultValue(value string, typ internal.Type) interface{} {
+	if value == "" {
+		return nil
+	}
+	literal, err := internal.ParseValue(value)
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		typ = nonNull.Type
+	}
+	if scalar, ok := typ.(*internal.Scalar); ok && (scalar.Name == "String" || scalar.Name == "ID") {
+		if s, ok := literal.(*ast.StringValue); ok && err == nil {
+			return s.Value
+		}
+		return value
+	}
+	if err != nil {
+		return value
+	}
+	parsed, err := internal.ValueToJson(literal, nil)
+	if err != nil {
+		return value
+	}
+	return parsed
+}
+
+// deprecationReason maps an introspected deprecation to the DeprecationReason of fields and values
+func deprecationReason(isDeprecated bool, reason string) *string {
+	if !isDeprecated {
+		return nil
+	}
+	return &reason
+}
+
 // parseSchema takes the introspected schema, validates the types,
 // and maps every field to the graphql types
 func parseSchema(schema *introspectionQueryResult) (*internal.Schema, error) {
@@ -269,6 +306,13 @@ func parseSchema(schema *introspectionQueryResult) (*internal.Schema, error) {
 		}
 	}
 
+	// the built-in scalars no field uses may be left out, but directives like @include use them
+	for name := range specifiedScalars {
+		if _, ok := all[name]; !ok {
+			all[name] = &internal.Scalar{Name: name}
+		}
+	}
+
 	// Initialize barebone types
 	for _, typ := range schema.Schema.Types {
 		switch typ.Kind {
@@ -287,10 +331,11 @@ func parseSchema(schema *introspectionQueryResult) (*internal.Schema, error) {
 				}
 
 				fields[field.Name] = &internal.Field{
-					Name: field.Name,
-					Desc: field.Desc,
-					Args: parsed,
-					Type: fieldTyp,
+					Name:              field.Name,
+					Desc:              field.Desc,
+					Args:              parsed,
+					Type:              fieldTyp,
+					DeprecationReason: deprecationReason(field.IsDeprecated, field.DeprecationReason),
 				}
 			}
 
@@ -322,10 +367,11 @@ func parseSchema(schema *introspectionQueryResult) (*internal.Schema, error) {
 				}
 
 				fields[field.Name] = &internal.Field{
-					Name: field.Name,
-					Desc: field.Desc,
-					Args: parsed,
-					Type: fieldTyp,
+					Name:              field.Name,
+					Desc:              field.Desc,
+					Args:              parsed,
+					Type:              fieldTyp,
+					DeprecationReason: deprecationReason(field.IsDeprecated, field.DeprecationReason),
 				}
 			}
 
@@ -382,14 +428,24 @@ func parseSchema(schema *introspectionQueryResult) (*internal.Schema, error) {
 			// XXX: introspection relies on the EnumValues map.
 			reverseMap := make(map[interface{}]string)
 			values := make([]string, 0, len(typ.EnumValues))
+			descs := make(map[string]string)
+			deprecated := make(map[string]string)
 			for _, value := range typ.EnumValues {
 				values = append(values, value.Name)
 				reverseMap[value.Name] = value.Name
+				if value.Desc != "" {
+					descs[value.Name] = value.Desc
+				}
+				if value.IsDeprecated {
+					deprecated[value.Name] = value.DeprecationReason
+				}
 			}
 
 			enum := all[typ.Name].(*internal.Enum)
 			enum.Values = values
 			enum.Map = reverseMap
+			enum.ValuesDesc = descs
+			enum.DeprecatedValues = deprecated
 
 		case "SCALAR":
 			// pass
@@ -399,13 +455,52 @@ func parseSchema(schema *introspectionQueryResult) (*internal.Schema, error) {
 		}
 	}
 
+	directives := make(map[string]*internal.Directive)
+	for _, directive := range schema.Schema.Directives {
+		args, err := parseInputFields(directive.Args, all)
+		if err != nil {
+			return nil, fmt.Errorf("directive %s: %v", directive.Name, err)
+		}
+		directives[directive.Name] = &internal.Directive{
+			Name:       directive.Name,
+			Desc:       directive.Desc,
+			Args:       args,
+			Locs:       directive.Locations,
+			Repeatable: directive.IsRepeatable,
+		}
+	}
+
+	// the roots are named by the schema, or after their operations in merged schemas
+	root := func(ref *introspectionTypeRef, name string) internal.Type {
+		if ref != nil {
+			name = ref.Name
+		}
+		if typ, ok := all[name]; ok {
+			return typ
+		}
+		return nil
+	}
+
 	return &internal.Schema{
-		TypeMap:  all,
-		Query:    all["Query"],
-		Mutation: all["Mutation"],
+		TypeMap:      all,
+		Directives:   directives,
+		Query:        root(schema.Schema.QueryType, "Query"),
+		Mutation:     root(schema.Schema.MutationType, "Mutation"),
+		Subscription: root(schema.Schema.SubscriptionType, "Subscription"),
 	}, nil
 }
 
+// ParseSchema builds a schema from the result of the introspection query, like the one of
+// introspection.ComputeSchemaJSON, to print it or validate queries against it. The schema has
+// no resolvers, so it can't execute queries.
+func ParseSchema(schemaJSON []byte) (*internal.Schema, error) {
+	result, err := parseIntrospection(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	return parseSchema(result)
+}
+
 // XXX: for types missing __federation, take intersection?
 
 // XXX: for (merged) unions, make sure we only send possible types
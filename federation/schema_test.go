@@ -0,0 +1,107 @@
+package federation_test
+
+import (
+	"github.com/shyptr/graphql/federation"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/printer"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const parseSDL = `
+"""A color."""
+enum Color {
+  RED
+  "Not red."
+  GREEN
+  BLUE @deprecated(reason: "gone")
+}
+
+input Filter {
+  "The name."
+  name: String = "none"
+  color: Color = RED
+  limit: Int = 10
+  old: Int @deprecated
+}
+
+type Item {
+  id: ID!
+  name(upper: Boolean = false): String @deprecated(reason: "use id")
+  colors: [Color!]
+}
+
+type Query {
+  items(filter: Filter): [Item!]!
+}
+
+type Mutation {
+  add(name: String!): Item
+}
+
+directive @upper(times: Int = 1) on FIELD | FRAGMENT_SPREAD
+`
+
+type parseItem struct {
+	ID     string   `graphql:"id"`
+	Name   string   `graphql:"name"`
+	Colors []string `graphql:"colors"`
+}
+
+type parseFilter struct {
+	Name  string `graphql:"name"`
+	Color string `graphql:"color"`
+	Limit int    `graphql:"limit"`
+	Old   int    `graphql:"old"`
+}
+
+func TestParseSchema(t *testing.T) {
+	schema := schemabuilder.MustBuildSDL(parseSDL, schemabuilder.Resolvers{
+		"Query.items": func(args struct {
+			Filter *parseFilter `graphql:"filter"`
+		}) []parseItem {
+			return nil
+		},
+		"Mutation.add": func(args struct {
+			Name string `graphql:"name"`
+		}) *parseItem {
+			return nil
+		},
+		"Item.name": func(item parseItem, args struct {
+			Upper bool `graphql:"upper"`
+		}) string {
+			return item.Name
+		},
+		"@upper": func(args struct {
+			Times *int `graphql:"times"`
+		}, field func() (interface{}, error)) (interface{}, error) {
+			return field()
+		},
+	})
+	expected := printer.PrintSchema(schema)
+	introspection.AddIntrospectionToSchema(schema)
+	schemaJSON, err := introspection.ComputeSchemaJSON(schema)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	parsed, err := federation.ParseSchema(schemaJSON)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected, printer.PrintSchema(parsed))
+
+	introspection.AddIntrospectionToSchema(parsed)
+	for query, errs := range map[string]string{
+		`{items(filter: {color: GREEN}){id name @upper(times: 2) @include(if: true)} __schema{types{name}}}`: ``,
+		`{items(filter: {color: PINK}){id}}`: `[graphql: Value "PINK" does not exist in "Color" enum. (1:24)]`,
+	} {
+		doc, err := internal.Parse(query)
+		if assert.NoError(t, err) {
+			assert.Equal(t, errs, validation.Validate(parsed, doc).Error(), query)
+		}
+	}
+}
@@ -4,6 +4,7 @@ go 1.13
 
 require (
 	cloud.google.com/go v0.50.0 // indirect
+	github.com/go-playground/universal-translator v0.17.0
 	github.com/go-playground/validator/v10 v10.2.0
 	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
 	github.com/golang/protobuf v1.3.5
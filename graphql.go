@@ -1,13 +1,19 @@
 package graphql
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/shyptr/graphql/ast"
 	"github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/execution"
 	"github.com/shyptr/graphql/internal"
-	"github.com/shyptr/graphql/schemabuilder"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -18,7 +24,70 @@ func Use(mm ...HandlerFunc) {
 type Handler struct {
 	Schema   *internal.Schema
 	Executor *execution.Executor
-	ctx      *Context
+	// Cache, when set, keeps the parsed queries between requests
+	Cache *QueryCache
+	// PersistedQueries, when set, enables automatic persisted queries
+	PersistedQueries PersistedQueryStore
+	// MaxCost, when positive, is the budget of execution.Cost an operation may not exceed.
+	// The cost of every operation is then reported in the cost extension of the response.
+	MaxCost int
+	// Tracing reports the timings of every request and its resolvers in the
+	// tracing extension of the response, in the Apollo tracing format.
+	Tracing bool
+	// ErrorPresenter, when set, turns every error of a response into the one sent to the client,
+	// for example to redact internal errors. The original errors are kept in Context.Error.
+	ErrorPresenter ErrorPresenter
+	// settings are the middleware, logger and limits of the handler, nil when it follows Ctx
+	settings *Context
+}
+
+// ErrorPresenter returns the error sent to the client in place of err.
+type ErrorPresenter func(ctx context.Context, err *errors.GraphQLError) *errors.GraphQLError
+
+// MaskErrors is an ErrorPresenter replacing the message of the errors of resolvers with
+// "internal error", unless they carry extensions, like the errors.Error meant for clients.
+func MaskErrors(ctx context.Context, err *errors.GraphQLError) *errors.GraphQLError {
+	if err.ResolverError == nil || errors.Extensions(err.ResolverError) != nil {
+		return err
+	}
+	masked := *err
+	masked.Message = "internal error"
+	return &masked
+}
+
+// present logs the panics among the errors of res and of its incremental payloads, whose clients
+// only get the ID, then applies the ErrorPresenter to them.
+func (h *Handler) present(ctx context.Context, res *Response) {
+	logPanics := func(errs errors.MultiError) {
+		for _, err := range errs {
+			if panicErr, ok := err.ResolverError.(*errors.PanicError); ok {
+				h.config().Logger.Print(panicErr.Detail())
+			}
+		}
+	}
+	logPanics(res.Errors)
+	for _, result := range res.Incremental {
+		logPanics(result.Errors)
+	}
+	if h.ErrorPresenter == nil {
+		return
+	}
+	presentAll := func(errs errors.MultiError) errors.MultiError {
+		if len(errs) == 0 {
+			return errs
+		}
+		presented := make(errors.MultiError, len(errs))
+		for i, err := range errs {
+			presented[i] = h.ErrorPresenter(ctx, err)
+		}
+		return presented
+	}
+	res.Errors = presentAll(res.Errors)
+	for i, result := range res.Incremental {
+		presented := *result
+		presented.Errors = presentAll(result.Errors)
+		res.Incremental[i] = &presented
+	}
 }
 
 // Resp represents a typical response of a GraphQL server. It may be encoded to JSON directly or
@@ -28,25 +97,33 @@ type Response struct {
 	Errors     []*errors.GraphQLError `json:"errors,omitempty"`
 	Data       interface{}            `json:"data,omitempty"`
 	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	// Incremental and HasNext are only set on the payloads of operations using @defer or @stream
+	Incremental []*execution.IncrementalResult `json:"incremental,omitempty"`
+	HasNext     *bool                          `json:"hasNext,omitempty"`
 }
 
-// HTTPHandler implements the handler required for executing the graphql queries and mutations
-func HTTPHandler(schema *internal.Schema) http.Handler {
+// HTTPHandler implements the handler required for executing the graphql queries and mutations.
+//
+// Without options, the handler follows the settings of Ctx made with Use, MaxDepth, SetLogger and the like.
+// With options, it starts from a copy of them, and later changes to Ctx no longer apply to it.
+func HTTPHandler(schema *internal.Schema, opts ...Option) http.Handler {
 	h := &Handler{
 		Schema:   schema,
 		Executor: &execution.Executor{},
 	}
-
+	if len(opts) > 0 {
+		settings := *Ctx
+		settings.HandlersChain = append([]HandlerFunc(nil), Ctx.HandlersChain...)
+		h.settings = &settings
+		for _, opt := range opts {
+			opt(h)
+		}
+	}
 	return h
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := *Ctx
-	ctx.Writer, ctx.Request = &Resp{ResponseWriter: w}, r
-	h.ctx = &ctx
-	h.ctx.keys = make(map[interface{}]interface{})
-	h.ctx.HandlersChain = append(h.ctx.HandlersChain, execute(h))
-	h.ctx.Next()
+	h.config().forRequest(w, r, execute(h)).Next()
 }
 
 func execute(handler *Handler) HandlerFunc {
@@ -54,99 +131,244 @@ func execute(handler *Handler) HandlerFunc {
 		if ctx.Request.Method == http.MethodOptions {
 			return
 		}
-		if ctx.Request.Method != http.MethodPost {
-			ctx.ServerError("must be post", http.StatusBadRequest)
-			return
+		if ctx.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx.parent, cancel = context.WithTimeout(ctx.parent, ctx.Timeout)
+			defer cancel()
 		}
-		param := execution.Params{Context: ctx}
-
-		contentType := strings.SplitN(ctx.Request.Header.Get("Content-Type"), ";", 2)[0]
-		if contentType == "multipart/form-data" {
-			if err := ctx.Request.ParseMultipartForm(200); err != nil {
-				ctx.ServerError(err.Error(), http.StatusBadRequest)
-				return
-			}
-			if err := json.Unmarshal([]byte(ctx.Request.Form.Get("operations")), &param); err != nil {
-				ctx.ServerError(err.Error(), http.StatusBadRequest)
-				return
-			}
-			var fileMap = map[string][]string{}
-			if err := json.Unmarshal([]byte(ctx.Request.Form.Get("map")), &fileMap); err != nil {
-				ctx.ServerError(err.Error(), http.StatusBadRequest)
-				return
+		params, batched, uploads, err := readParams(ctx)
+		defer closeUploads(uploads)
+		if err != nil {
+			status := http.StatusBadRequest
+			if err, ok := err.(*statusError); ok {
+				status = err.status
 			}
-			if param.Variables == nil {
-				param.Variables = make(map[string]interface{})
-			}
-			for key, path := range fileMap {
-				file, header, err := ctx.Request.FormFile(key)
-				if err != nil {
-					ctx.ServerError(err.Error(), http.StatusBadRequest)
-					return
-				}
-				varPath := strings.Split(path[0], ".")[1:]
-				var index int
-				for ; index < len(varPath); index++ {
-					if index < len(varPath)-1 {
-						param.Variables[varPath[index]] = make(map[string]interface{})
-					} else {
-						param.Variables[varPath[index]] = schemabuilder.Upload{
-							File:     file,
-							Filename: header.Filename,
-							Size:     header.Size,
-						}
-					}
-				}
-			}
-		} else {
-			if err := json.NewDecoder(ctx.Request.Body).Decode(&param); err != nil {
-				ctx.ServerError(err.Error(), http.StatusBadRequest)
-				return
+			if status == http.StatusMethodNotAllowed {
+				ctx.Writer.Header().Set("Allow", "GET, POST")
 			}
+			ctx.ServerError(err.Error(), status)
+			return
 		}
-
-		ctx.OperationName = param.OperationName
-		var execute interface{}
-		var exeErr errors.MultiError
-		defer func() {
-			res := &Response{
-				Data:   execute,
-				Errors: exeErr,
-			}
-			if len(exeErr) > 0 {
-				ctx.Error = append(ctx.Error, exeErr...)
-			}
-			responseJSON, err := json.Marshal(res)
-			if err != nil {
-				ctx.ServerError(err.Error(), http.StatusInternalServerError)
-				return
-			}
-			if ctx.Writer.status == 0 {
-				ctx.Writer.WriteHeader(http.StatusOK)
-			}
-			ctx.Writer.Header().Set("Content-Type", "application/json")
-			ctx.Writer.Write(responseJSON)
-		}()
-		doc, parseErr := internal.Parse(param.Query)
-		if parseErr != nil {
-			exeErr = []*errors.GraphQLError{parseErr.(*errors.GraphQLError)}
+		if batched {
+			handler.executeBatch(ctx, params)
 			return
 		}
-		//exeErr = validation.Validate(handler.Schema, doc, param.Variables, ctx.MaxDepth)
-		//if len(exeErr) > 0 {
-		//	return
-		//}
 
-		operationType, selectionSet, applyErr := execution.ApplySelectionSet(handler.Schema, doc, param.OperationName, param.Variables)
-		if applyErr != nil {
-			exeErr = []*errors.GraphQLError{applyErr.(*errors.GraphQLError)}
+		op := handler.newOperation(ctx, params[0])
+		if op.response == nil {
+			op.execute(handler, acceptsIncremental(ctx.Request))
+		}
+		op.finish()
+		if op.incremental != nil {
+			writeIncremental(ctx, op.response, op.incremental, handler.present, op.endTracing)
+			return
+		}
+		handler.present(ctx, op.response)
+		responseJSON, err := json.Marshal(op.response)
+		if err != nil {
+			ctx.ServerError(err.Error(), http.StatusInternalServerError)
 			return
 		}
-		ctx.Method = operationType
-		root := handler.Schema.Query
-		if operationType == ast.Mutation {
-			root = handler.Schema.Mutation
+		mediaType := responseMediaType(ctx.Request)
+		status := op.status
+		// clients of application/json expect 200 whatever the errors
+		if op.failed && status == http.StatusOK && mediaType == graphqlResponseJSON {
+			status = http.StatusBadRequest
+		}
+		ctx.Writer.Header().Set("Content-Type", mediaType)
+		if ctx.Writer.status == 0 {
+			ctx.Writer.WriteHeader(status)
+		}
+		ctx.Writer.Write(responseJSON)
+	}
+}
+
+// statusError is an error reading a request which is answered with status instead of 400.
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+var errMethodNotAllowed = &statusError{http.StatusMethodNotAllowed, "only GET and POST requests are supported"}
+
+// readParams reads the operations sent in the request of ctx, batched is set when they were sent
+// as a JSON array. The files uploaded along with them are to be closed once they are executed.
+func readParams(ctx *Context) (params []execution.Params, batched bool, uploads []multipart.File, err error) {
+	r := ctx.Request
+	var param execution.Params
+	contentType := strings.SplitN(r.Header.Get("Content-Type"), ";", 2)[0]
+	if r.Method == http.MethodGet {
+		if err := paramsFromURL(r.URL.Query(), &param); err != nil {
+			return nil, false, nil, err
+		}
+	} else if r.Method != http.MethodPost {
+		return nil, false, nil, errMethodNotAllowed
+	} else if contentType == "application/graphql" {
+		// the body is the query, the other params are in the URL
+		if err := paramsFromURL(r.URL.Query(), &param); err != nil {
+			return nil, false, nil, err
+		}
+		query, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		param.Query = string(query)
+	} else if contentType == "multipart/form-data" {
+		return readMultipart(ctx)
+	} else {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+			if err := json.Unmarshal(body, &params); err != nil {
+				return nil, false, nil, err
+			}
+			return params, true, nil, nil
+		}
+		if err := json.Unmarshal(body, &param); err != nil {
+			return nil, false, nil, err
+		}
+	}
+	return []execution.Params{param}, false, nil, nil
+}
+
+// operation is one operation of a request, from its params to its response.
+type operation struct {
+	ctx           *Context
+	tracing       *execution.Tracing
+	operationType ast.OperationType
+	root          internal.Type
+	selectionSet  *internal.SelectionSet
+	extensions    map[string]interface{}
+	// response is set once the operation is executed, or as soon as it fails before
+	response    *Response
+	incremental <-chan *execution.IncrementalResult
+	// failed is set when the operation is not executed at all
+	failed bool
+	status int
+}
+
+// newOperation prepares the operation of param for ctx. Its response is already set
+// when it can't be executed.
+func (h *Handler) newOperation(ctx *Context, param execution.Params) *operation {
+	op := &operation{ctx: ctx, extensions: map[string]interface{}{}, status: http.StatusOK}
+	if h.Tracing {
+		op.tracing = execution.NewTracing()
+		execution.WithTracing(ctx, op.tracing)
+	}
+	param.Context = ctx
+	ctx.OperationName = param.OperationName
+	fail := func(errs ...*errors.GraphQLError) *operation {
+		op.response = &Response{Errors: errs, Extensions: op.extensions}
+		op.failed = true
+		return op
+	}
+
+	if err := h.persistedQuery(ctx, &param); err != nil {
+		return fail(err)
+	}
+	operationType, selectionSet, applyErr := h.prepare(param.Query, param.OperationName, param.Variables, ctx.limits(), op.tracing)
+	if applyErr != nil {
+		if errs, ok := applyErr.(errors.MultiError); ok {
+			return fail(errs...)
+		}
+		return fail(applyErr.(*errors.GraphQLError))
+	}
+	if operationType == ast.Mutation && ctx.Request.Method == http.MethodGet {
+		ctx.Writer.Header().Set("Allow", "POST")
+		op.status = http.StatusMethodNotAllowed
+		return fail(errors.New("mutations can only be sent over POST"))
+	}
+	ctx.Method = operationType
+	op.operationType, op.selectionSet = operationType, selectionSet
+	op.root = h.Schema.Query
+	if operationType == ast.Mutation {
+		op.root = h.Schema.Mutation
+	}
+	if h.MaxCost > 0 {
+		cost := execution.Cost(h.Schema, op.root, selectionSet)
+		op.extensions["cost"] = map[string]interface{}{"requestedQueryCost": cost, "maximumAvailable": h.MaxCost}
+		if cost > h.MaxCost {
+			return fail(&errors.GraphQLError{
+				Message:    fmt.Sprintf("operation cost %d exceeds the maximum cost %d", cost, h.MaxCost),
+				Locations:  []errors.Location{selectionSet.Loc},
+				Rule:       "MaxCost",
+				Extensions: map[string]interface{}{"code": "COST_LIMIT_EXCEEDED", "cost": cost, "maxCost": h.MaxCost},
+			})
+		}
+	}
+	return op
+}
+
+// execute runs the operation, incremental tells whether the payloads of @defer and @stream may come later.
+func (op *operation) execute(h *Handler, incremental bool) {
+	op.response = &Response{Extensions: op.extensions}
+	if incremental {
+		op.response.Data, op.response.Errors, op.incremental = h.Executor.ExecuteIncremental(op.ctx, op.operationType, op.root, nil, op.selectionSet)
+		return
+	}
+	op.response.Data, op.response.Errors = h.Executor.ExecuteOperation(op.ctx, op.operationType, op.root, nil, op.selectionSet)
+}
+
+// finish ends the tracing of the operation and records its errors in its Context. The tracing
+// of an operation with subsequent payloads only ends with the last of them.
+func (op *operation) finish() {
+	if op.incremental == nil {
+		op.endTracing(op.response)
+	}
+	if len(op.response.Errors) > 0 {
+		op.ctx.Error = append(op.ctx.Error, op.response.Errors...)
+	}
+}
+
+// endTracing ends the tracing of the operation, if any, and reports it in the extensions of res.
+func (op *operation) endTracing(res *Response) {
+	if op.tracing == nil {
+		return
+	}
+	op.tracing.Finish()
+	if res.Extensions == nil {
+		res.Extensions = map[string]interface{}{}
+	}
+	res.Extensions["tracing"] = op.tracing
+}
+
+const graphqlResponseJSON = "application/graphql-response+json"
+
+// responseMediaType returns application/graphql-response+json when the client accepts it before
+// application/json, and application/json otherwise, as clients which predate it expect.
+func responseMediaType(r *http.Request) string {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case graphqlResponseJSON, "application/json":
+			return mediaType
+		}
+	}
+	return "application/json"
+}
+
+// paramsFromURL reads the params of a GET request from its URL query.
+func paramsFromURL(values url.Values, param *execution.Params) error {
+	param.Query = values.Get("query")
+	param.OperationName = values.Get("operationName")
+	if variables := values.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &param.Variables); err != nil {
+			return err
+		}
+	}
+	if extensions := values.Get("extensions"); extensions != "" {
+		if err := json.Unmarshal([]byte(extensions), &param.Extensions); err != nil {
+			return err
 		}
-		execute, exeErr = handler.Executor.Execute(ctx, root, nil, selectionSet)
 	}
+	return nil
 }
@@ -0,0 +1,626 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/shyptr/graphql"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHandler_MaxCost(t *testing.T) {
+	type Item struct {
+		ID int `graphql:"id"`
+	}
+	build := schemabuilder.NewSchema()
+	build.Object("Item", Item{})
+	build.Query().FieldFunc("items", func() []*Item { return []*Item{{1}} }, "", schemabuilder.Cost(3))
+	handler := &graphql.Handler{
+		Schema:   build.MustBuild(),
+		Executor: &execution.Executor{},
+		MaxCost:  5,
+	}
+
+	post := func(body string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		res, err := ioutil.ReadAll(w.Body)
+		assert.NoError(t, err)
+		return string(res)
+	}
+
+	assert.JSONEq(t, `{
+	"data": {"items": [{"id": 1}]},
+	"extensions": {"cost": {"requestedQueryCost": 3, "maximumAvailable": 5}}
+}`, post(`{"query": "{ items { id } }"}`))
+	assert.JSONEq(t, `{
+	"errors": [{
+		"message": "operation cost 6 exceeds the maximum cost 5",
+		"locations": [{"line": 1, "column": 1}],
+		"extensions": {"code": "COST_LIMIT_EXCEEDED", "cost": 6, "maxCost": 5}
+	}],
+	"extensions": {"cost": {"requestedQueryCost": 6, "maximumAvailable": 5}}
+}`, post(`{"query": "{ items { id } again: items { id } }"}`))
+}
+
+func TestHandler_Tracing(t *testing.T) {
+	type Item struct {
+		ID int `graphql:"id"`
+	}
+	build := schemabuilder.NewSchema()
+	build.Object("Item", Item{})
+	build.Query().FieldFunc("items", func() []*Item { return []*Item{{1}, {2}} }, "")
+	handler := &graphql.Handler{
+		Schema:   build.MustBuild(),
+		Executor: &execution.Executor{},
+		Tracing:  true,
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "{ items { id } }"}`)))
+	var res struct {
+		Extensions struct {
+			Tracing struct {
+				Version    int       `json:"version"`
+				StartTime  time.Time `json:"startTime"`
+				EndTime    time.Time `json:"endTime"`
+				Duration   int64     `json:"duration"`
+				Parsing    struct{ StartOffset, Duration int64 }
+				Validation struct{ StartOffset, Duration int64 }
+				Execution  struct {
+					Resolvers []struct {
+						Path        []interface{} `json:"path"`
+						ParentType  string        `json:"parentType"`
+						FieldName   string        `json:"fieldName"`
+						ReturnType  string        `json:"returnType"`
+						StartOffset int64         `json:"startOffset"`
+						Duration    int64         `json:"duration"`
+					} `json:"resolvers"`
+				} `json:"execution"`
+			} `json:"tracing"`
+		} `json:"extensions"`
+	}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+
+	tracing := res.Extensions.Tracing
+	assert.Equal(t, 1, tracing.Version)
+	assert.False(t, tracing.EndTime.Before(tracing.StartTime))
+	assert.True(t, tracing.Duration > 0)
+	assert.True(t, tracing.Parsing.Duration > 0)
+	assert.True(t, tracing.Validation.StartOffset >= tracing.Parsing.StartOffset+tracing.Parsing.Duration)
+
+	var paths []string
+	for _, resolver := range tracing.Execution.Resolvers {
+		path, _ := json.Marshal(resolver.Path)
+		paths = append(paths, resolver.ParentType+"."+resolver.FieldName+": "+resolver.ReturnType+" at "+string(path))
+		assert.True(t, resolver.StartOffset >= tracing.Validation.StartOffset+tracing.Validation.Duration)
+		assert.True(t, resolver.StartOffset+resolver.Duration <= tracing.Duration)
+	}
+	assert.ElementsMatch(t, []string{
+		`Query.items: [Item] at ["items"]`,
+		`Item.id: Int! at ["items",0,"id"]`,
+		`Item.id: Int! at ["items",1,"id"]`,
+	}, paths)
+}
+
+func TestHandler_Incremental(t *testing.T) {
+	type Item struct {
+		ID int `graphql:"id"`
+	}
+	build := schemabuilder.NewSchema()
+	build.Object("Item", Item{}).FieldFunc("slow", func(item *Item) string { return "slow" }, "")
+	build.Query().FieldFunc("item", func() *Item { return &Item{1} }, "")
+	handler := &graphql.Handler{
+		Schema:   build.MustBuild(),
+		Executor: &execution.Executor{},
+	}
+	body := `{"query": "{ item { id ... @defer(label: \"slow\") { slow } } }"}`
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Accept", "multipart/mixed")
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, `multipart/mixed; boundary="-"`, w.Header().Get("Content-Type"))
+	var parts []string
+	reader := multipart.NewReader(w.Body, "-")
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "application/json; charset=utf-8", part.Header.Get("Content-Type"))
+		res, err := ioutil.ReadAll(part)
+		assert.NoError(t, err)
+		parts = append(parts, string(res))
+	}
+	if assert.Len(t, parts, 3) {
+		assert.JSONEq(t, `{"data": {"item": {"id": 1}}, "hasNext": true}`, parts[0])
+		assert.JSONEq(t, `{"incremental": [{"data": {"slow": "slow"}, "path": ["item"], "label": "slow"}], "hasNext": true}`, parts[1])
+		assert.JSONEq(t, `{"hasNext": false}`, parts[2])
+	}
+
+	// clients which don't accept multipart responses get everything at once
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	assert.JSONEq(t, `{"data": {"item": {"id": 1, "slow": "slow"}}}`, w.Body.String())
+
+	// the tracing comes with the last payload, with the resolvers of the deferred fragments
+	handler.Tracing = true
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Accept", "multipart/mixed")
+	handler.ServeHTTP(w, r)
+	var payloads []map[string]interface{}
+	reader = multipart.NewReader(w.Body, "-")
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		var payload map[string]interface{}
+		assert.NoError(t, json.NewDecoder(part).Decode(&payload))
+		payloads = append(payloads, payload)
+	}
+	if assert.Len(t, payloads, 3) {
+		assert.NotContains(t, payloads[0], "extensions")
+		var fields []interface{}
+		tracing := payloads[2]["extensions"].(map[string]interface{})["tracing"].(map[string]interface{})
+		for _, resolver := range tracing["execution"].(map[string]interface{})["resolvers"].([]interface{}) {
+			fields = append(fields, resolver.(map[string]interface{})["fieldName"])
+		}
+		assert.ElementsMatch(t, []interface{}{"item", "id", "slow"}, fields)
+	}
+}
+
+func TestHandler_ErrorPresenter(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("internal", func() (*string, error) { return nil, fmt.Errorf("db password is hunter2") }, "")
+	build.Query().FieldFunc("coded", func() (*string, error) { return nil, errors.Errorf("FORBIDDEN", "not allowed") }, "")
+	var logged []string
+	handler := &graphql.Handler{
+		Schema:   build.MustBuild(),
+		Executor: &execution.Executor{},
+		ErrorPresenter: func(ctx context.Context, err *errors.GraphQLError) *errors.GraphQLError {
+			logged = append(logged, err.Message)
+			return graphql.MaskErrors(ctx, err)
+		},
+	}
+
+	post := func(body string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		return w.Body.String()
+	}
+	assert.JSONEq(t, `{
+	"data": {"internal": null},
+	"errors": [{"message": "internal error", "locations": [{"line": 1, "column": 3}], "path": ["internal"]}]
+}`, post(`{"query": "{ internal }"}`))
+	assert.JSONEq(t, `{
+	"data": {"coded": null},
+	"errors": [{"message": "not allowed", "locations": [{"line": 1, "column": 3}], "path": ["coded"], "extensions": {"code": "FORBIDDEN"}}]
+}`, post(`{"query": "{ coded }"}`))
+	assert.Equal(t, []string{"db password is hunter2", "not allowed"}, logged)
+}
+
+func TestHandler_LogsPanics(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("boom", func() *string { panic("boom") }, "")
+	var logged bytes.Buffer
+	handler := graphql.HTTPHandler(build.MustBuild(), graphql.WithLogger(log.New(&logged, "", 0)))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "{ boom }"}`)))
+	var res struct {
+		Errors []struct {
+			Extensions struct {
+				CorrelationID string `json:"correlationId"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	if assert.Len(t, res.Errors, 1) {
+		id := res.Errors[0].Extensions.CorrelationID
+		assert.NotEmpty(t, id)
+		assert.Contains(t, logged.String(), "panic "+id+": boom")
+	}
+}
+
+func TestHandler_Validation(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("a", func() string { return "a" }, "")
+	handler := &graphql.Handler{Schema: build.MustBuild(), Executor: &execution.Executor{}, Cache: graphql.NewQueryCache(10)}
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "{ a(x: 1) b }"}`)))
+		assert.JSONEq(t, `{
+	"errors": [
+		{"message": "Unknown argument \"x\" on field \"a\".", "locations": [{"line": 1, "column": 5}]},
+		{"message": "Cannot query field \"b\" on type \"Query\".", "locations": [{"line": 1, "column": 11}]}
+	]
+}`, w.Body.String())
+	}
+	assert.Equal(t, 0, handler.Cache.Stats().Len)
+}
+
+func TestHandler_Timeout(t *testing.T) {
+	graphql.Timeout(10 * time.Millisecond)
+	defer graphql.Timeout(0)
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("a", func() string { return "a" }, "")
+	build.Mutation().FieldFunc("slow", func(ctx context.Context) (*string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, "")
+	build.Mutation().FieldFunc("next", func() *string {
+		t.Error("next is resolved after the timeout")
+		return nil
+	}, "")
+	handler := &graphql.Handler{Schema: build.MustBuild(), Executor: &execution.Executor{}}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "mutation { slow next }"}`)))
+	assert.JSONEq(t, `{
+	"data": {"slow": null, "next": null},
+	"errors": [
+		{"message": "context deadline exceeded", "locations": [{"line": 1, "column": 12}], "path": ["slow"]},
+		{"message": "field abandoned: context deadline exceeded", "locations": [{"line": 1, "column": 17}], "path": ["next"], "extensions": {"code": "TIMEOUT"}}
+	]
+}`, w.Body.String())
+
+	// the request is cancelled when the client goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "mutation { slow }"}`)).WithContext(ctx))
+	assert.Contains(t, w.Body.String(), `"message":"field abandoned: context canceled"`)
+	assert.Contains(t, w.Body.String(), `"code":"CANCELLED"`)
+}
+
+func TestHTTPHandler_Options(t *testing.T) {
+	type Item struct {
+		Name string `graphql:"name"`
+	}
+	build := schemabuilder.NewSchema()
+	build.Object("Item", Item{})
+	build.Query().FieldFunc("item", func() Item { return Item{Name: "a"} }, "")
+	schema := build.MustBuild()
+
+	var seen []string
+	mark := func(name string) graphql.HandlerFunc {
+		return func(ctx *graphql.Context) {
+			seen = append(seen, name)
+			ctx.Next()
+		}
+	}
+	public := graphql.HTTPHandler(schema, graphql.WithMaxDepth(1), graphql.WithMiddleware(mark("public")))
+	admin := graphql.HTTPHandler(schema, graphql.WithMiddleware(mark("admin")))
+	post := func(handler http.Handler, query string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"query": "`+query+`"}`)))
+		return w.Body.String()
+	}
+
+	assert.Contains(t, post(public, "{ item { name } }"), "Field nesting must not be deeper than 1.")
+	// the limits are checked before the operation is validated
+	assert.JSONEq(t, `{"errors": [{"message": "Field nesting must not be deeper than 1.", "locations": [{"line": 1, "column": 10}]}]}`,
+		post(public, "{ item { name } unknown }"))
+	assert.JSONEq(t, `{"data": {"item": {"name": "a"}}}`, post(admin, "{ item { name } }"))
+	assert.Equal(t, []string{"public", "public", "admin"}, seen)
+	assert.Empty(t, graphql.Ctx.HandlersChain)
+	assert.Equal(t, 50, graphql.Ctx.MaxDepth)
+}
+
+func TestHandler_ConcurrentRequests(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("user", func(ctx context.Context) string {
+		return ctx.Value("user").(string)
+	}, "")
+	build.Query().FieldFunc("operation", func(ctx context.Context) string {
+		return graphql.GetContext(ctx).OperationName
+	}, "")
+	schema := build.MustBuild()
+
+	// the middleware reads the request, writes the Context and sees the errors of the operation
+	auth := func(ctx *graphql.Context) {
+		ctx.Set("user", ctx.Request.Header.Get("User"))
+		ctx.Next()
+		if len(ctx.Error) > 0 {
+			t.Errorf("unexpected errors: %v", ctx.Error)
+		}
+	}
+	handlers := map[string]http.Handler{
+		"options": graphql.HTTPHandler(schema, graphql.WithMiddleware(auth), graphql.WithTimeout(time.Minute)),
+		"struct":  &graphql.Handler{Schema: schema, Executor: &execution.Executor{}, Cache: graphql.NewQueryCache(10)},
+	}
+	graphql.Use(auth)
+	defer func() { graphql.Ctx.HandlersChain = nil }()
+
+	for name, handler := range handlers {
+		handler := handler
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for i := 0; i < 300; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					user := fmt.Sprint("user", i)
+					body := fmt.Sprintf(`{"query": "query op%d { user operation }", "operationName": "op%d"}`, i%3, i%3)
+					r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+					r.Header.Set("User", user)
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, r)
+					assert.JSONEq(t, fmt.Sprintf(`{"data": {"user": %q, "operation": "op%d"}}`, user, i%3), w.Body.String())
+				}(i)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestHandler_HTTP(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hello", func(args struct {
+		Name string `graphql:"name"`
+	}) string {
+		return "hello " + args.Name
+	}, "")
+	build.Query().FieldFunc("fail", func() (*string, error) { return nil, fmt.Errorf("failed") }, "")
+	build.Mutation().FieldFunc("hello", func() string {
+		t.Error("a mutation is executed over GET")
+		return ""
+	}, "")
+	handler := graphql.HTTPHandler(build.MustBuild())
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(httptest.NewRequest("GET", "/?"+url.Values{
+		"query":         {"query q($name: String!) { hello(name: $name) } query r { fail }"},
+		"variables":     {`{"name": "world"}`},
+		"operationName": {"q"},
+	}.Encode(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"data": {"hello": "hello world"}}`, w.Body.String())
+
+	w = serve(httptest.NewRequest("GET", "/?query="+url.QueryEscape("mutation { hello }"), nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "POST", w.Header().Get("Allow"))
+	assert.JSONEq(t, `{"errors": [{"message": "mutations can only be sent over POST"}]}`, w.Body.String())
+
+	w = serve(httptest.NewRequest("PUT", "/", strings.NewReader(`{"query": "{ hello }"}`)))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, POST", w.Header().Get("Allow"))
+
+	r := httptest.NewRequest("POST", "/?variables="+url.QueryEscape(`{"name": "graphql"}`),
+		strings.NewReader(`query ($name: String!) { hello(name: $name) }`))
+	r.Header.Set("Content-Type", "application/graphql; charset=utf-8")
+	assert.JSONEq(t, `{"data": {"hello": "hello graphql"}}`, serve(r).Body.String())
+
+	// application/graphql-response+json reports the requests which are not executed with a 4xx status
+	post := func(accept, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(fmt.Sprintf(`{"query": %q}`, query)))
+		r.Header.Set("Accept", accept)
+		return serve(r)
+	}
+	w = post("application/graphql-response+json, application/json;q=0.9", "{ unknown }")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/graphql-response+json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `Cannot query field \"unknown\" on type \"Query\".`)
+	w = post("application/graphql-response+json", "{ fail }")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+	"data": {"fail": null},
+	"errors": [{"message": "failed", "locations": [{"line": 1, "column": 3}], "path": ["fail"]}]
+}`, w.Body.String())
+	w = post("application/json, application/graphql-response+json", "{ unknown }")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestHandler_Batch(t *testing.T) {
+	var fetches, count int
+	var mu sync.Mutex
+	user := func(ctx context.Context, args struct {
+		ID int `graphql:"id"`
+	}) (string, error) {
+		loader := schemabuilder.GetLoader(ctx, "user", func(ctx context.Context, keys []interface{}) ([]interface{}, error) {
+			mu.Lock()
+			fetches++
+			mu.Unlock()
+			values := make([]interface{}, len(keys))
+			for i, key := range keys {
+				values[i] = fmt.Sprintf("user %d", key)
+			}
+			return values, nil
+		})
+		name, err := loader.Load(ctx, args.ID)
+		if err != nil {
+			return "", err
+		}
+		return name.(string), nil
+	}
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("user", user, "")
+	build.Query().FieldFunc("count", func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return count
+	}, "")
+	build.Mutation().FieldFunc("increment", func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		return count
+	}, "")
+	handler := graphql.HTTPHandler(build.MustBuild(), graphql.WithMaxBatchSize(5))
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		return w
+	}
+
+	w := post(`[
+	{"query": "{ user(id: 1) count }"},
+	{"query": "query q($id: Int!) { user(id: $id) }", "variables": {"id": 1}},
+	{"query": "mutation { increment }"},
+	{"query": "{ count }"},
+	{"query": "{ unknown }"}
+]`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+	{"data": {"user": "user 1", "count": 0}},
+	{"data": {"user": "user 1"}},
+	{"data": {"increment": 1}},
+	{"data": {"count": 1}},
+	{"errors": [{"message": "Cannot query field \"unknown\" on type \"Query\".", "locations": [{"line": 1, "column": 3}]}]}
+]`, w.Body.String())
+	assert.Equal(t, 1, fetches)
+
+	w = post(`[{"query": "{ count }"}, {"query": "{ count }"}, {"query": "{ count }"}, {"query": "{ count }"}, {"query": "{ count }"}, {"query": "{ count }"}]`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "a batch must not contain more than 5 operations")
+	assert.Equal(t, http.StatusBadRequest, post(`[]`).Code)
+}
+
+func TestHandler_Uploads(t *testing.T) {
+	type Attachment struct {
+		Name string               `graphql:"name"`
+		File schemabuilder.Upload `graphql:"file"`
+	}
+	read := func(upload schemabuilder.Upload) string {
+		content, err := ioutil.ReadAll(upload.File)
+		assert.NoError(t, err)
+		upload.File.Seek(0, io.SeekStart)
+		return fmt.Sprintf("%s:%d:%s", upload.Filename, upload.Size, content)
+	}
+	build := schemabuilder.NewSchema()
+	build.InputObject("Attachment", Attachment{})
+	build.Mutation().FieldFunc("upload", func(args struct {
+		File        *schemabuilder.Upload  `graphql:"file"`
+		Files       []schemabuilder.Upload `graphql:"files"`
+		Attachments []Attachment           `graphql:"attachments"`
+	}) []string {
+		var uploaded []string
+		if args.File != nil {
+			uploaded = append(uploaded, read(*args.File))
+		}
+		for _, file := range args.Files {
+			uploaded = append(uploaded, read(file))
+		}
+		for _, attachment := range args.Attachments {
+			uploaded = append(uploaded, attachment.Name+"="+read(attachment.File))
+		}
+		return uploaded
+	}, "")
+	handler := graphql.HTTPHandler(build.MustBuild(), graphql.WithMaxUploadSize(8), graphql.WithMaxUploads(3))
+	post := func(operations, fileMap string, files ...string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		assert.NoError(t, mw.WriteField("operations", operations))
+		assert.NoError(t, mw.WriteField("map", fileMap))
+		for i, content := range files {
+			part, err := mw.CreateFormFile(fmt.Sprint(i), fmt.Sprintf("%d.txt", i))
+			assert.NoError(t, err)
+			part.Write([]byte(content))
+		}
+		assert.NoError(t, mw.Close())
+		r := httptest.NewRequest("POST", "/", body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	const mutation = `mutation ($file: Upload, $files: [Upload!], $attachments: [Attachment!]) ` +
+		`{ upload(file: $file, files: $files, attachments: $attachments) }`
+
+	w := post(fmt.Sprintf(`{"query": %q, "variables": {
+	"file": null,
+	"files": [null, null],
+	"attachments": [{"name": "a", "file": null}, {"name": "b", "file": null}]
+}}`, mutation), `{
+	"0": ["variables.file", "variables.attachments.1.file"],
+	"1": ["variables.files.0", "variables.attachments.0.file"],
+	"2": ["variables.files.1"]
+}`, "zero", "one", "two")
+	assert.JSONEq(t, `{"data": {"upload": [
+	"0.txt:4:zero", "1.txt:3:one", "2.txt:3:two", "a=1.txt:3:one", "b=0.txt:4:zero"
+]}}`, w.Body.String())
+
+	w = post(fmt.Sprintf(`[
+	{"query": %q, "variables": {"file": null, "files": null, "attachments": null}},
+	{"query": %[1]q, "variables": {"file": null, "files": [null], "attachments": null}}
+]`, mutation),
+		`{"0": ["0.variables.file"], "1": ["1.variables.files.0"]}`, "zero", "one")
+	assert.JSONEq(t, `[{"data": {"upload": ["0.txt:4:zero"]}}, {"data": {"upload": ["1.txt:3:one"]}}]`, w.Body.String())
+
+	operations := fmt.Sprintf(`{"query": %q, "variables": {"file": null, "files": null, "attachments": null}}`, mutation)
+	w = post(operations, `{"0": ["variables.file"]}`, "too large!")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `file "0.txt" must not be larger than 8 bytes`)
+	w = post(operations, `{"0": ["variables.file"], "1": ["variables.file"], "2": ["variables.file"], "3": ["variables.file"]}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	w = post(operations, `{"0": ["variables.file"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `file "0" is missing`)
+	w = post(operations, `{"0": ["variables.file.name"]}`, "zero")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "variables.file is neither an object nor a list")
+}
+
+func TestHandler_UploadsOnDisk(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Mutation().FieldFunc("upload", func(args struct {
+		Files []schemabuilder.Upload `graphql:"files"`
+	}) []bool {
+		var onDisk []bool
+		for _, file := range args.Files {
+			_, ok := file.File.(*os.File)
+			onDisk = append(onDisk, ok)
+		}
+		return onDisk
+	}, "")
+	handler := graphql.HTTPHandler(build.MustBuild())
+
+	// the files of a request share the memory they are kept in, the third one doesn't fit anymore
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	assert.NoError(t, mw.WriteField("operations", `{"query": "mutation ($files: [Upload!]) { upload(files: $files) }", "variables": {"files": [null, null, null]}}`))
+	assert.NoError(t, mw.WriteField("map", `{"0": ["variables.files.0"], "1": ["variables.files.1"], "2": ["variables.files.2"]}`))
+	for i := 0; i < 3; i++ {
+		part, err := mw.CreateFormFile(fmt.Sprint(i), fmt.Sprintf("%d.bin", i))
+		assert.NoError(t, err)
+		part.Write(make([]byte, 4<<20))
+	}
+	assert.NoError(t, mw.Close())
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.JSONEq(t, `{"data": {"upload": [false, false, true]}}`, w.Body.String())
+}
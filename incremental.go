@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/shyptr/graphql/execution"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// acceptsIncremental reports whether the client accepts the multipart/mixed responses
+// of @defer and @stream. Other clients get the whole response at once.
+func acceptsIncremental(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "multipart/mixed")
+}
+
+// writeIncremental writes initial and the subsequent payloads of an operation, once presented,
+// as the parts of a multipart/mixed response, each flushed as soon as it is ready. last completes
+// the last payload.
+func writeIncremental(ctx *Context, initial *Response, results <-chan *execution.IncrementalResult,
+	present func(context.Context, *Response), last func(*Response)) {
+	ctx.Writer.Header().Set("Content-Type", `multipart/mixed; boundary="-"`)
+	ctx.Writer.WriteHeader(http.StatusOK)
+	mw := multipart.NewWriter(ctx.Writer)
+	mw.SetBoundary("-")
+	write := func(res *Response) error {
+		present(ctx, res)
+		part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=utf-8"}})
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(part).Encode(res); err != nil {
+			return err
+		}
+		if flusher, ok := ctx.Writer.ResponseWriter.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	hasNext := true
+	initial.HasNext = &hasNext
+	if err := write(initial); err != nil {
+		for range results {
+		}
+		return
+	}
+	if err := deliverIncremental(results, write, last); err != nil {
+		return
+	}
+	mw.Close()
+}
+
+// deliverIncremental writes the subsequent payloads as they come, those which are ready
+// together are written at once, and ends with a payload telling that nothing is left, completed
+// by last unless it is nil. It drains results even when writing fails.
+func deliverIncremental(results <-chan *execution.IncrementalResult, write func(*Response) error, last func(*Response)) error {
+	hasNext := true
+	for result := range results {
+		res := &Response{Incremental: []*execution.IncrementalResult{result}, HasNext: &hasNext}
+	ready:
+		for {
+			select {
+			case result, ok := <-results:
+				if !ok {
+					break ready
+				}
+				res.Incremental = append(res.Incremental, result)
+			default:
+				break ready
+			}
+		}
+		if err := write(res); err != nil {
+			for range results {
+			}
+			return err
+		}
+	}
+	done := false
+	res := &Response{HasNext: &done}
+	if last != nil {
+		last(res)
+	}
+	return write(res)
+}
@@ -16,6 +16,10 @@ type lexer struct {
 	next                  rune
 	comment               bytes.Buffer
 	useStringDescriptions bool
+	// blockString is the value of the block string when next is token.BLOCK_STRING
+	blockString string
+	// commented is set once a comment was skipped
+	commented bool
 }
 
 func NewLexer(source string, useStringDescriptions ...bool) *lexer {
@@ -70,15 +74,68 @@ func (l *lexer) SkipWhitespace() {
 			l.skipComment()
 			continue
 		}
+		// text/scanner reads """ as an empty string followed by a quote
+		if l.next == token.STRING && l.scan.TokenText() == `""` && l.scan.Peek() == '"' {
+			l.scan.Next()
+			l.next = token.BLOCK_STRING
+			l.blockString = l.scanBlockString()
+		}
 		break
 	}
 }
 
+// scanBlockString reads a block string up to its closing quotes, the opening ones being read already.
+func (l *lexer) scanBlockString() string {
+	var raw strings.Builder
+	for {
+		next := l.scan.Next()
+		if next == scanner.EOF {
+			l.SyntaxError("Unterminated string.")
+		}
+		raw.WriteRune(next)
+		s := raw.String()
+		if strings.HasSuffix(s, `"""`) && !strings.HasSuffix(s, `\"""`) {
+			return blockStringValue(strings.Replace(s[:len(s)-3], `\"""`, `"""`, -1))
+		}
+	}
+}
+
+// blockStringValue removes the indentation common to the lines of raw but the first,
+// and the leading and trailing blank lines, as the spec does with block strings.
+func blockStringValue(raw string) string {
+	raw = strings.Replace(raw, "\r\n", "\n", -1)
+	lines := strings.Split(strings.Replace(raw, "\r", "\n", -1), "\n")
+	commonIndent := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < len(line) && (commonIndent == -1 || indent < commonIndent) {
+			commonIndent = indent
+		}
+	}
+	if commonIndent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) < commonIndent {
+				lines[i] = ""
+			} else {
+				lines[i] = lines[i][commonIndent:]
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (l *lexer) skipComment() {
 	if l.next != '#' {
 		panic("consumeComment used in wrong context")
 	}
 
+	l.commented = true
 	// TODO: count and trim whitespace so we can dedent any following lines.
 	if l.scan.Peek() == ' ' {
 		l.scan.Next()
@@ -97,6 +154,14 @@ func (l *lexer) skipComment() {
 	}
 }
 
+// HasComments reports whether source, a valid document, holds comments, which the parsed documents leave out.
+func HasComments(source string) bool {
+	l := NewLexer(source)
+	for l.SkipWhitespace(); l.next != scanner.EOF && !l.commented; l.SkipWhitespace() {
+	}
+	return l.commented
+}
+
 // If the next token is of the given kind, advance and skip whitespace.
 // Otherwise, do not change the parser state and return error.
 func (l *lexer) advance(expected rune) {
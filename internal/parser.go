@@ -24,6 +24,10 @@ func Parse(source string) (*Document, error) {
 			operations = append(operations, o)
 		case *ast.FragmentDefinition:
 			fragments = append(fragments, o)
+		default:
+			err := errors.New("The %s definition is not executable.", definitionName(o))
+			err.Locations = []errors.Location{o.Location()}
+			return nil, err
 		}
 	}
 	return &Document{
@@ -32,6 +36,46 @@ func Parse(source string) (*Document, error) {
 	}, nil
 }
 
+// definitionName names a type system definition or extension in error messages.
+func definitionName(definition ast.Definition) string {
+	var name *ast.Name
+	switch d := definition.(type) {
+	case *ast.SchemaDefinition, *ast.SchemaExtension:
+		return "schema"
+	case *ast.DirectiveDefinition:
+		return fmt.Sprintf(`"@%s"`, d.Name.Name)
+	case *ast.ScalarDefinition:
+		name = d.Name
+	case *ast.ObjectDefinition:
+		name = d.Name
+	case *ast.InterfaceDefinition:
+		name = d.Name
+	case *ast.UnionDefinition:
+		name = d.Name
+	case *ast.EnumDefinition:
+		name = d.Name
+	case *ast.InputObjectDefinition:
+		name = d.Name
+	case *ast.ScalarExtension:
+		name = d.Name
+	case *ast.ObjectExtension:
+		name = d.Name
+	case *ast.InterfaceExtension:
+		name = d.Name
+	case *ast.UnionExtension:
+		name = d.Name
+	case *ast.EnumExtension:
+		name = d.Name
+	case *ast.InputObjectExtension:
+		name = d.Name
+	}
+	if name == nil {
+		return definition.GetKind()
+	}
+	return strconv.Quote(name.Name)
+}
+
+// ParseDocument parses a document of operations and fragments, or of type system definitions and extensions.
 func ParseDocument(source string) (*ast.Document, *errors.GraphQLError) {
 	if source == "" {
 		return nil, errors.New("Must provide source. Received: undefined.")
@@ -48,6 +92,24 @@ func ParseDocument(source string) (*ast.Document, *errors.GraphQLError) {
 	return doc, nil
 }
 
+// ParseValue parses a constant value literal, like the default values in the results of the introspection query.
+func ParseValue(source string) (ast.Value, *errors.GraphQLError) {
+	l := NewLexer(source, false)
+
+	var value ast.Value
+	err := l.catchSyntaxError(func() {
+		l.SkipWhitespace()
+		value = ParseValueLiteral(l, true)
+		if l.peek() != token.EOF {
+			l.SyntaxError(fmt.Sprintf(`Unexpected %q.`, l.scan.TokenText()))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
 func parseDocument(l *lexer) *ast.Document {
 	doc := &ast.Document{Kind: kinds.Document, Loc: l.location()}
 	l.SkipWhitespace()
@@ -60,19 +122,35 @@ func parseDocument(l *lexer) *ast.Document {
 		}
 
 		loc := l.location()
-		switch name := parseName(l); name.Name {
+		desc := parseDescription(l)
+		name := parseName(l)
+		switch name.Name {
+		case token.QUERY, token.MUTATION, token.SUBSCRIPTION, token.FRAGMENT, token.EXTEND:
+			if desc != nil {
+				l.SyntaxError(fmt.Sprintf("Unexpected description before %q.", name.Name))
+			}
+		}
+		switch name.Name {
 		case "query":
 			definition := parseOperationDefinition(l, ast.Query)
 			definition.Loc = loc
 			doc.Definition = append(doc.Definition, definition)
 		case "mutation":
-			doc.Definition = append(doc.Definition, parseOperationDefinition(l, ast.Mutation))
+			definition := parseOperationDefinition(l, ast.Mutation)
+			definition.Loc = loc
+			doc.Definition = append(doc.Definition, definition)
 		case "subscription":
-			doc.Definition = append(doc.Definition, parseOperationDefinition(l, ast.Mutation))
+			definition := parseOperationDefinition(l, ast.Subscription)
+			definition.Loc = loc
+			doc.Definition = append(doc.Definition, definition)
 		case "fragment":
 			fragment := parseFragmentDefinition(l)
 			fragment.Loc = loc
 			doc.Definition = append(doc.Definition, fragment)
+		case token.SCHEMA, token.SCALAR, token.TYPE, token.INTERFACE, token.UNION, token.ENUM, token.INPUT, token.DIRECTIVE:
+			doc.Definition = append(doc.Definition, parseTypeSystemDefinition(l, name.Name, desc, loc))
+		case token.EXTEND:
+			doc.Definition = append(doc.Definition, parseTypeSystemExtension(l, loc))
 		default:
 			l.SyntaxError(fmt.Sprintf(`Unexpected %q.`, name.Name))
 		}
@@ -244,16 +322,16 @@ func parseSelection(l *lexer) ast.Selection {
 }
 
 /**
- * Arguments : ( Argument+ )
+ * Arguments[Const] : ( Argument[?Const]+ )
  */
-func parseArguments(l *lexer) []*ast.Argument {
+func parseArguments(l *lexer, constOnly bool) []*ast.Argument {
 	var args []*ast.Argument
 	l.advance(token.PAREN_L)
 	for l.peek() != token.PAREN_R {
 		loc := l.location()
 		name := parseName(l)
 		l.advance(token.COLON)
-		value := ParseValueLiteral(l, false)
+		value := ParseValueLiteral(l, constOnly)
 		args = append(args, &ast.Argument{Kind: kinds.Argument, Name: name, Value: value, Loc: loc})
 	}
 	l.advance(token.PAREN_R)
@@ -300,6 +378,10 @@ func ParseValueLiteral(l *lexer, constOnly bool) ast.Value {
 		value = strings.TrimSuffix(value, `"`)
 		l.advance(token.STRING)
 		return &ast.StringValue{Kind: kinds.StringValue, Value: value, Loc: loc}
+	case token.BLOCK_STRING:
+		value := l.blockString
+		l.advance(token.BLOCK_STRING)
+		return &ast.StringValue{Kind: kinds.StringValue, Value: value, Loc: loc}
 	case token.RAWSTRING:
 		value := l.scan.TokenText()
 		value = strings.TrimPrefix(value, "`")
@@ -390,7 +472,7 @@ func parseField(l *lexer) *ast.Field {
 		field.Name = parseName(l)
 	}
 	if l.peek() == token.PAREN_L {
-		field.Arguments = parseArguments(l)
+		field.Arguments = parseArguments(l, false)
 	}
 	field.Directives = parseDirectives(l)
 	if l.peek() == token.BRACE_L {
@@ -438,15 +520,26 @@ func parseFragment(l *lexer) ast.Selection {
 func parseDirectives(l *lexer) []*ast.Directive {
 	var directives []*ast.Directive
 	for l.peek() == token.AT {
-		directives = append(directives, parseDirective(l))
+		directives = append(directives, parseDirective(l, false))
+	}
+	return directives
+}
+
+/**
+ * Directives[Const] : Directive[Const]+
+ */
+func parseConstDirectives(l *lexer) []*ast.Directive {
+	var directives []*ast.Directive
+	for l.peek() == token.AT {
+		directives = append(directives, parseDirective(l, true))
 	}
 	return directives
 }
 
 /**
- * Directive : @ Name Arguments?
+ * Directive[Const] : @ Name Arguments[?Const]?
  */
-func parseDirective(l *lexer) *ast.Directive {
+func parseDirective(l *lexer, constOnly bool) *ast.Directive {
 	loc := l.location()
 	l.advance(token.AT)
 	directive := &ast.Directive{Kind: kinds.Directive}
@@ -454,7 +547,7 @@ func parseDirective(l *lexer) *ast.Directive {
 	directive.Name.Loc.Column--
 	directive.Loc = loc
 	if l.peek() == token.PAREN_L {
-		directive.Args = parseArguments(l)
+		directive.Args = parseArguments(l, constOnly)
 	}
 	return directive
 }
@@ -323,6 +323,173 @@ func TestParser(t *testing.T) {
 	})
 }
 
+func TestParseTypeSystem(t *testing.T) {
+	t.Run("parses type system definitions", func(t *testing.T) {
+		doc, err := internal.ParseDocument(`
+      """
+      The schema.
+      """
+      schema @tag(name: "a") { query: Root mutation: Mutation }
+
+      "A date."
+      scalar Date @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+
+      type Root implements & Node & Named @key(fields: "id") {
+        """
+          Finds a pet,
+            by its name.
+        """
+        pet(
+          "The name."
+          name: String! = "Odie" @deprecated
+          kinds: [Kind!] = [DOG]
+        ): Pet
+        id: ID!
+      }
+
+      interface Named implements Node { name: String }
+
+      union Pet = | Dog | Cat
+
+      enum Kind { "A dog." DOG @deprecated(reason: "use CAT") CAT }
+
+      input Filter { name: String = null limit: Int = 10 }
+
+      """Upper cases."""
+      directive @upper(if: Boolean = true) repeatable on | FIELD | FRAGMENT_SPREAD
+    `)
+		if !assert.Equal(t, NilGraphQLError, err) {
+			return
+		}
+		assert.Len(t, doc.Definition, 8)
+
+		schema := doc.Definition[0].(*ast.SchemaDefinition)
+		assert.Equal(t, "The schema.", schema.Desc.Value)
+		assert.Equal(t, "tag", schema.Directives[0].Name.Name)
+		assert.Equal(t, ast.Query, schema.OperationTypes[0].Operation)
+		assert.Equal(t, "Root", schema.OperationTypes[0].Type.Name.Name)
+		assert.Equal(t, ast.Mutation, schema.OperationTypes[1].Operation)
+
+		scalar := doc.Definition[1].(*ast.ScalarDefinition)
+		assert.Equal(t, "A date.", scalar.Desc.Value)
+		assert.Equal(t, "Date", scalar.Name.Name)
+		assert.Equal(t, errors.Location{Line: 7, Column: 7}, scalar.Loc)
+
+		root := doc.Definition[2].(*ast.ObjectDefinition)
+		assert.Equal(t, "Root", root.Name.Name)
+		assert.Equal(t, "Node", root.Interfaces[0].Name.Name)
+		assert.Equal(t, "Named", root.Interfaces[1].Name.Name)
+		assert.Equal(t, "key", root.Directives[0].Name.Name)
+		pet := root.Fields[0]
+		assert.Equal(t, "Finds a pet,\n  by its name.", pet.Desc.Value)
+		assert.Equal(t, "Pet", pet.Type.String())
+		assert.Equal(t, "The name.", pet.Argument[0].Desc.Value)
+		assert.Equal(t, "String!", pet.Argument[0].Type.String())
+		assert.Equal(t, "Odie", pet.Argument[0].DefaultValue.GetValue())
+		assert.Equal(t, "deprecated", pet.Argument[0].Directives[0].Name.Name)
+		assert.Equal(t, kinds.ListValue, pet.Argument[1].DefaultValue.GetKind())
+		assert.Equal(t, "ID!", root.Fields[1].Type.String())
+
+		named := doc.Definition[3].(*ast.InterfaceDefinition)
+		assert.Equal(t, "Node", named.Interfaces[0].Name.Name)
+		assert.Equal(t, "name", named.Fields[0].Name.Name)
+
+		union := doc.Definition[4].(*ast.UnionDefinition)
+		assert.Equal(t, "Dog", union.Members[0].Name.Name)
+		assert.Equal(t, "Cat", union.Members[1].Name.Name)
+
+		enum := doc.Definition[5].(*ast.EnumDefinition)
+		assert.Equal(t, "DOG", enum.Values[0].Value.Value)
+		assert.Equal(t, "A dog.", enum.Values[0].Desc.Value)
+		assert.Equal(t, "deprecated", enum.Values[0].Directives[0].Name.Name)
+		assert.Equal(t, "CAT", enum.Values[1].Value.Value)
+
+		input := doc.Definition[6].(*ast.InputObjectDefinition)
+		assert.Equal(t, kinds.NullValue, input.InputFields[0].DefaultValue.GetKind())
+		assert.Equal(t, "10", input.InputFields[1].DefaultValue.GetValue())
+
+		directive := doc.Definition[7].(*ast.DirectiveDefinition)
+		assert.Equal(t, "Upper cases.", directive.Desc.Value)
+		assert.Equal(t, "upper", directive.Name.Name)
+		assert.Equal(t, "if", directive.Arguments[0].Name.Name)
+		assert.True(t, directive.Repeatable)
+		assert.Equal(t, []string{"FIELD", "FRAGMENT_SPREAD"}, directive.Locations)
+	})
+
+	t.Run("parses type system extensions", func(t *testing.T) {
+		doc, err := internal.ParseDocument(`
+      extend schema @tag
+      extend schema { subscription: Subscription }
+      extend scalar Date @tag
+      extend type Root implements Node
+      extend type Root @tag
+      extend type Root { name: String }
+      extend interface Named { nickname: String }
+      extend union Pet = Bird
+      extend enum Kind { BIRD }
+      extend input Filter @tag
+    `)
+		if !assert.Equal(t, NilGraphQLError, err) {
+			return
+		}
+		assert.Len(t, doc.Definition, 10)
+		assert.Equal(t, "tag", doc.Definition[0].(*ast.SchemaExtension).Directives[0].Name.Name)
+		assert.Equal(t, ast.Subscription, doc.Definition[1].(*ast.SchemaExtension).RootOperation[0].Operation)
+		assert.Equal(t, "Date", doc.Definition[2].(*ast.ScalarExtension).Name.Name)
+		assert.Equal(t, "Node", doc.Definition[3].(*ast.ObjectExtension).Interfaces[0].Name.Name)
+		assert.Equal(t, "tag", doc.Definition[4].(*ast.ObjectExtension).Directives[0].Name.Name)
+		assert.Equal(t, "name", doc.Definition[5].(*ast.ObjectExtension).Fields[0].Name.Name)
+		assert.Equal(t, "nickname", doc.Definition[6].(*ast.InterfaceExtension).Fields[0].Name.Name)
+		assert.Equal(t, "Bird", doc.Definition[7].(*ast.UnionExtension).Members[0].Name.Name)
+		assert.Equal(t, "BIRD", doc.Definition[8].(*ast.EnumExtension).Values[0].Value.Value)
+		assert.Equal(t, "tag", doc.Definition[9].(*ast.InputObjectExtension).Directives[0].Name.Name)
+	})
+
+	t.Run("parses block strings as values", func(t *testing.T) {
+		doc, err := internal.ParseDocument(`{ field(arg: """
+        a \""" quote
+          "and" a \n
+      """) }`)
+		if !assert.Equal(t, NilGraphQLError, err) {
+			return
+		}
+		assert.Equal(t, "a \"\"\" quote\n  \"and\" a \\n", doc.Definition[0].(*ast.OperationDefinition).SelectionSet.
+			Selections[0].(*ast.Field).Arguments[0].Value.GetValue())
+	})
+
+	t.Run("type system errors", func(t *testing.T) {
+		tests := []struct {
+			source  string
+			message string
+		}{
+			{`extend type Root`, `Syntax Error: Unexpected "EOF".`},
+			{`extend type Root {}`, `Syntax Error: Unexpected "EOF".`},
+			{`extend query Root`, `Syntax Error: Unexpected "query".`},
+			{`"description" query { a }`, `Syntax Error: Unexpected description before "query".`},
+			{`type Root { a(b: Int = $c): Int }`, `Syntax Error: Unexpected "\"$\"".`},
+			{`type Root @key(fields: $a) { a: Int }`, `Syntax Error: Unexpected "\"$\"".`},
+			{`enum Kind { true }`, `Syntax Error: true is reserved and cannot be used for an enum value.`},
+			{`directive @a on FIELD | SOMEWHERE`, `Syntax Error: Unexpected "SOMEWHERE".`},
+			{`schema { query: Query, fragment: Fragment }`, `Syntax Error: Unexpected "fragment".`},
+			{`type Root { a: """unterminated }`, `Syntax Error: Unterminated string.`},
+		}
+		for _, test := range tests {
+			_, err := internal.ParseDocument(test.source)
+			if assert.NotNil(t, err, test.source) {
+				assert.Equal(t, test.message, err.Message, test.source)
+			}
+		}
+	})
+
+	t.Run("does not execute type system definitions", func(t *testing.T) {
+		_, err := internal.Parse("{ a }\ntype Root { a: Int }")
+		assert.Equal(t, &errors.GraphQLError{
+			Message:   `The "Root" definition is not executable.`,
+			Locations: []errors.Location{{Line: 2, Column: 1}},
+		}, err)
+	})
+}
+
 func TestParseValueLiteral(t *testing.T) {
 	t.Run("parses null value", func(t *testing.T) {
 		lexer := internal.NewLexer("null")
@@ -0,0 +1,386 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/kinds"
+	"github.com/shyptr/graphql/token"
+	"text/scanner"
+)
+
+// directiveLocations are the locations a directive definition may name.
+var directiveLocations = map[string]bool{
+	"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true, "FRAGMENT_DEFINITION": true,
+	"FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true, "VARIABLE_DEFINITION": true,
+	"SCHEMA": true, "SCALAR": true, "OBJECT": true, "FIELD_DEFINITION": true, "ARGUMENT_DEFINITION": true,
+	"INTERFACE": true, "UNION": true, "ENUM": true, "ENUM_VALUE": true, "INPUT_OBJECT": true,
+	"INPUT_FIELD_DEFINITION": true,
+}
+
+/**
+ * Description : StringValue
+ */
+func parseDescription(l *lexer) *ast.StringValue {
+	if l.peek() != token.STRING && l.peek() != token.BLOCK_STRING {
+		return nil
+	}
+	return ParseValueLiteral(l, true).(*ast.StringValue)
+}
+
+/**
+ * TypeSystemDefinition :
+ *   - SchemaDefinition
+ *   - TypeDefinition
+ *   - DirectiveDefinition
+ *
+ * The keyword starting the definition, and the description before it, are read already.
+ */
+func parseTypeSystemDefinition(l *lexer, keyword string, desc *ast.StringValue, loc errors.Location) ast.TypeSystemDefinition {
+	switch keyword {
+	case token.SCHEMA:
+		return &ast.SchemaDefinition{
+			Kind:           kinds.SchemaDefinition,
+			Desc:           desc,
+			Directives:     parseConstDirectives(l),
+			OperationTypes: parseOperationTypeDefinitions(l),
+			Loc:            loc,
+		}
+	case token.SCALAR:
+		return &ast.ScalarDefinition{
+			Kind:       kinds.ScalarDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Directives: parseConstDirectives(l),
+			Loc:        loc,
+		}
+	case token.TYPE:
+		return &ast.ObjectDefinition{
+			Kind:       kinds.ObjectDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseConstDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+	case token.INTERFACE:
+		return &ast.InterfaceDefinition{
+			Kind:       kinds.InterfaceDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseConstDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+	case token.UNION:
+		return &ast.UnionDefinition{
+			Kind:       kinds.UnionDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Directives: parseConstDirectives(l),
+			Members:    parseUnionMemberTypes(l),
+			Loc:        loc,
+		}
+	case token.ENUM:
+		return &ast.EnumDefinition{
+			Kind:       kinds.EnumDefinition,
+			Desc:       desc,
+			Name:       parseName(l),
+			Directives: parseConstDirectives(l),
+			Values:     parseEnumValuesDefinition(l),
+			Loc:        loc,
+		}
+	case token.INPUT:
+		return &ast.InputObjectDefinition{
+			Kind:        kinds.InputObjectDefinition,
+			Desc:        desc,
+			Name:        parseName(l),
+			Directives:  parseConstDirectives(l),
+			InputFields: parseInputFieldsDefinition(l),
+			Loc:         loc,
+		}
+	case token.DIRECTIVE:
+		return parseDirectiveDefinition(l, desc, loc)
+	}
+	panic(syntaxError(fmt.Sprintf("Unexpected %q.", keyword)))
+}
+
+/**
+ * TypeSystemExtension :
+ *   - SchemaExtension
+ *   - TypeExtension
+ *
+ * An extension must add something, the `extend` keyword is read already.
+ */
+func parseTypeSystemExtension(l *lexer, loc errors.Location) ast.TypeSystemExtension {
+	keyword := l.scan.TokenText()
+	l.advance(token.NAME)
+	var extension ast.TypeSystemExtension
+	var empty bool
+	switch keyword {
+	case token.SCHEMA:
+		schema := &ast.SchemaExtension{Directives: parseConstDirectives(l), Loc: loc}
+		if l.peek() == token.BRACE_L {
+			schema.RootOperation = parseOperationTypeDefinitions(l)
+		}
+		extension, empty = schema, len(schema.Directives) == 0 && len(schema.RootOperation) == 0
+	case token.SCALAR:
+		scalar := &ast.ScalarExtension{Name: parseName(l), Directives: parseConstDirectives(l), Loc: loc}
+		extension, empty = scalar, len(scalar.Directives) == 0
+	case token.TYPE:
+		object := &ast.ObjectExtension{
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseConstDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+		extension, empty = object, len(object.Interfaces) == 0 && len(object.Directives) == 0 && len(object.Fields) == 0
+	case token.INTERFACE:
+		iface := &ast.InterfaceExtension{
+			Name:       parseName(l),
+			Interfaces: parseImplementsInterfaces(l),
+			Directives: parseConstDirectives(l),
+			Fields:     parseFieldsDefinition(l),
+			Loc:        loc,
+		}
+		extension, empty = iface, len(iface.Interfaces) == 0 && len(iface.Directives) == 0 && len(iface.Fields) == 0
+	case token.UNION:
+		union := &ast.UnionExtension{Name: parseName(l), Directives: parseConstDirectives(l), Loc: loc}
+		union.Members = parseUnionMemberTypes(l)
+		extension, empty = union, len(union.Directives) == 0 && len(union.Members) == 0
+	case token.ENUM:
+		enum := &ast.EnumExtension{Name: parseName(l), Directives: parseConstDirectives(l), Loc: loc}
+		enum.Values = parseEnumValuesDefinition(l)
+		extension, empty = enum, len(enum.Directives) == 0 && len(enum.Values) == 0
+	case token.INPUT:
+		input := &ast.InputObjectExtension{Name: parseName(l), Directives: parseConstDirectives(l), Loc: loc}
+		input.InputFields = parseInputFieldsDefinition(l)
+		extension, empty = input, len(input.Directives) == 0 && len(input.InputFields) == 0
+	default:
+		l.SyntaxError(fmt.Sprintf("Unexpected %q.", keyword))
+	}
+	if empty {
+		l.SyntaxError(fmt.Sprintf("Unexpected %q.", scanner.TokenString(l.peek())))
+	}
+	return extension
+}
+
+/**
+ * RootOperationTypeDefinitions : { OperationTypeDefinition+ }
+ *
+ * OperationTypeDefinition : OperationType : NamedType
+ */
+func parseOperationTypeDefinitions(l *lexer) []*ast.OperationTypeDefinition {
+	var operationTypes []*ast.OperationTypeDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		loc := l.location()
+		var operation ast.OperationType
+		switch name := parseName(l); name.Name {
+		case token.QUERY:
+			operation = ast.Query
+		case token.MUTATION:
+			operation = ast.Mutation
+		case token.SUBSCRIPTION:
+			operation = ast.Subscription
+		default:
+			l.SyntaxError(fmt.Sprintf("Unexpected %q.", name.Name))
+		}
+		l.advance(token.COLON)
+		operationTypes = append(operationTypes, &ast.OperationTypeDefinition{
+			Kind:      kinds.OperationTypeDefinition,
+			Operation: operation,
+			Type:      parseNamed(l),
+			Loc:       loc,
+		})
+	}
+	l.advance(token.BRACE_R)
+	return operationTypes
+}
+
+/**
+ * ImplementsInterfaces :
+ *   - implements `&`? NamedType
+ *   - ImplementsInterfaces & NamedType
+ */
+func parseImplementsInterfaces(l *lexer) []*ast.Named {
+	if l.peek() != token.NAME || l.scan.TokenText() != "implements" {
+		return nil
+	}
+	l.advance(token.NAME)
+	if l.peek() == token.AMP {
+		l.advance(token.AMP)
+	}
+	interfaces := []*ast.Named{parseNamed(l)}
+	for l.peek() == token.AMP {
+		l.advance(token.AMP)
+		interfaces = append(interfaces, parseNamed(l))
+	}
+	return interfaces
+}
+
+/**
+ * FieldsDefinition : { FieldDefinition+ }
+ *
+ * FieldDefinition : Description? Name ArgumentsDefinition? : Type Directives[Const]?
+ */
+func parseFieldsDefinition(l *lexer) []*ast.FieldDefinition {
+	if l.peek() != token.BRACE_L {
+		return nil
+	}
+	var fields []*ast.FieldDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		loc := l.location()
+		field := &ast.FieldDefinition{Kind: kinds.FieldDefinition, Desc: parseDescription(l), Loc: loc}
+		field.Name = parseName(l)
+		field.Argument = parseArgumentsDefinition(l)
+		l.advance(token.COLON)
+		field.Type = ParseType(l)
+		field.Directives = parseConstDirectives(l)
+		fields = append(fields, field)
+	}
+	l.advance(token.BRACE_R)
+	return fields
+}
+
+/**
+ * ArgumentsDefinition : ( InputValueDefinition+ )
+ */
+func parseArgumentsDefinition(l *lexer) []*ast.InputValueDefinition {
+	if l.peek() != token.PAREN_L {
+		return nil
+	}
+	var args []*ast.InputValueDefinition
+	l.advance(token.PAREN_L)
+	for l.peek() != token.PAREN_R {
+		args = append(args, parseInputValueDefinition(l))
+	}
+	l.advance(token.PAREN_R)
+	return args
+}
+
+/**
+ * InputFieldsDefinition : { InputValueDefinition+ }
+ */
+func parseInputFieldsDefinition(l *lexer) []*ast.InputValueDefinition {
+	if l.peek() != token.BRACE_L {
+		return nil
+	}
+	var fields []*ast.InputValueDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		fields = append(fields, parseInputValueDefinition(l))
+	}
+	l.advance(token.BRACE_R)
+	return fields
+}
+
+/**
+ * InputValueDefinition : Description? Name : Type DefaultValue? Directives[Const]?
+ */
+func parseInputValueDefinition(l *lexer) *ast.InputValueDefinition {
+	loc := l.location()
+	value := &ast.InputValueDefinition{Kind: kinds.InputValueDefinition, Desc: parseDescription(l), Loc: loc}
+	value.Name = parseName(l)
+	l.advance(token.COLON)
+	value.Type = ParseType(l)
+	if l.peek() == token.EQUALS {
+		l.advance(token.EQUALS)
+		value.DefaultValue = ParseValueLiteral(l, true)
+	}
+	value.Directives = parseConstDirectives(l)
+	return value
+}
+
+/**
+ * UnionMemberTypes :
+ *   - = `|`? NamedType
+ *   - UnionMemberTypes | NamedType
+ */
+func parseUnionMemberTypes(l *lexer) []*ast.Named {
+	if l.peek() != token.EQUALS {
+		return nil
+	}
+	l.advance(token.EQUALS)
+	if l.peek() == token.PIPE {
+		l.advance(token.PIPE)
+	}
+	members := []*ast.Named{parseNamed(l)}
+	for l.peek() == token.PIPE {
+		l.advance(token.PIPE)
+		members = append(members, parseNamed(l))
+	}
+	return members
+}
+
+/**
+ * EnumValuesDefinition : { EnumValueDefinition+ }
+ *
+ * EnumValueDefinition : Description? EnumValue Directives[Const]?
+ *
+ * EnumValue : Name but not `true`, `false` or `null`
+ */
+func parseEnumValuesDefinition(l *lexer) []*ast.EnumValueDefinition {
+	if l.peek() != token.BRACE_L {
+		return nil
+	}
+	var values []*ast.EnumValueDefinition
+	l.advance(token.BRACE_L)
+	for l.peek() != token.BRACE_R {
+		loc := l.location()
+		desc := parseDescription(l)
+		valueLoc := l.location()
+		name := parseName(l)
+		switch name.Name {
+		case "true", "false", "null":
+			l.SyntaxError(fmt.Sprintf("%s is reserved and cannot be used for an enum value.", name.Name))
+		}
+		values = append(values, &ast.EnumValueDefinition{
+			Kind:       kinds.EnumValueDefinition,
+			Desc:       desc,
+			Value:      &ast.EnumValue{Kind: kinds.EnumValue, Value: name.Name, Loc: valueLoc},
+			Directives: parseConstDirectives(l),
+			Loc:        loc,
+		})
+	}
+	l.advance(token.BRACE_R)
+	return values
+}
+
+/**
+ * DirectiveDefinition : Description? directive @ Name ArgumentsDefinition? `repeatable`? on DirectiveLocations
+ *
+ * DirectiveLocations :
+ *   - `|`? DirectiveLocation
+ *   - DirectiveLocations | DirectiveLocation
+ */
+func parseDirectiveDefinition(l *lexer, desc *ast.StringValue, loc errors.Location) *ast.DirectiveDefinition {
+	l.advance(token.AT)
+	directive := &ast.DirectiveDefinition{Kind: kinds.DirectiveDefinition, Desc: desc, Loc: loc}
+	directive.Name = parseName(l)
+	directive.Arguments = parseArgumentsDefinition(l)
+	if l.peek() == token.NAME && l.scan.TokenText() == "repeatable" {
+		l.advance(token.NAME)
+		directive.Repeatable = true
+	}
+	l.advanceKeyWord("on")
+	if l.peek() == token.PIPE {
+		l.advance(token.PIPE)
+	}
+	for {
+		location := parseName(l).Name
+		if !directiveLocations[location] {
+			l.SyntaxError(fmt.Sprintf("Unexpected %q.", location))
+		}
+		directive.Locations = append(directive.Locations, location)
+		if l.peek() != token.PIPE {
+			break
+		}
+		l.advance(token.PIPE)
+	}
+	return directive
+}
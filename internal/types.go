@@ -91,6 +91,8 @@ type Enum struct {
 	ReverseMap map[string]interface{} `json:"-"`
 	Map        map[interface{}]string `json:"-"`
 	Desc       string                 `json:"description"`
+	// DeprecatedValues holds the deprecation reasons of the deprecated values, by name
+	DeprecatedValues map[string]string `json:"-"`
 }
 
 // An input object defines a structured collection of fields which may be supplied to a field argument.
@@ -153,6 +155,9 @@ type TypeResolve func(ctx context.Context, value interface{}) *Object
 
 type FieldResolve func(ctx context.Context, source, args interface{}) (interface{}, error)
 
+// BatchFieldResolve resolves a field for many sources at once, it returns one result per source, in the same order.
+type BatchFieldResolve func(ctx context.Context, sources []interface{}, args interface{}) ([]interface{}, error)
+
 //type HandlerFunc func(ctx context.Context) error
 
 type Field struct {
@@ -161,13 +166,25 @@ type Field struct {
 	Args    map[string]*InputField `json:"arguments"`
 	Resolve FieldResolve           `json:"-"`
 	Desc    string                 `json:"desc"`
+	// BatchResolve is set when the field can be resolved for a list of sources in one call,
+	// Resolve is always set as well and resolves a single source.
+	BatchResolve BatchFieldResolve `json:"-"`
+	// Cost overrides the default cost of the field in the cost analysis of queries.
+	Cost *int `json:"-"`
+	// DeprecationReason is set when the field is deprecated.
+	DeprecationReason *string `json:"deprecationReason,omitempty"`
 }
 
+// DefaultDeprecationReason is the reason of the deprecations which don't give one.
+const DefaultDeprecationReason = "No longer supported"
+
 type InputField struct {
 	Name         string      `json:"name"`
 	Type         Type        `json:"type"`
 	Desc         string      `json:"description"`
 	DefaultValue interface{} `json:"defaultValue"`
+	// DeprecationReason is set when the argument or input field is deprecated.
+	DeprecationReason *string `json:"deprecationReason,omitempty"`
 }
 
 //Schema used to validate and resolve the queries
@@ -189,6 +206,8 @@ type Directive struct {
 	FnResolve DirectiveFn            `json:"-"`
 	Locs      []string               `json:"locations"`
 	Loc       errors.Location
+	// Repeatable is set when the directive may be used more than once at a location.
+	Repeatable bool `json:"repeatable"`
 }
 
 type Document struct {
@@ -237,7 +256,9 @@ type Selection struct {
 	Args         interface{}
 	SelectionSet *SelectionSet
 	Directives   []*Directive
-	Loc          errors.Location
+	// Stream is set when the items of the list are streamed with @stream
+	Stream *Stream
+	Loc    errors.Location
 }
 
 // A FragmentDefinition represents a reusable part of a GraphQL query
@@ -258,6 +279,19 @@ type FragmentSpread struct {
 	Loc        errors.Location
 	Fragment   *FragmentDefinition
 	Directives []*Directive
+	// Defer is set when the fragment is deferred with @defer
+	Defer *Defer
+}
+
+// Defer asks for the selections of a fragment to be delivered after the rest of the response.
+type Defer struct {
+	Label string
+}
+
+// Stream asks for the items of a list after the InitialCount first ones to be delivered one by one.
+type Stream struct {
+	Label        string
+	InitialCount int
 }
 
 func IsInputType(typ Type) bool {
@@ -17,6 +17,7 @@ query IntrospectionQuery {
 			args {
 				...InputValue
 			}
+			isRepeatable
 		}
 	}
 }
@@ -27,7 +28,7 @@ fragment FullType on __Type {
 	fields(includeDeprecated: true) {
 		name
 		description
-		args {
+		args(includeDeprecated: true) {
 			...InputValue
 		}
 		type {
@@ -36,7 +37,7 @@ fragment FullType on __Type {
 		isDeprecated
 		deprecationReason
 	}
-	inputFields {
+	inputFields(includeDeprecated: true) {
 		...InputValue
 	}
 	interfaces {
@@ -57,6 +58,8 @@ fragment InputValue on __InputValue {
 	description
 	type { ...TypeRef }
 	defaultValue
+	isDeprecated
+	deprecationReason
 }
 fragment TypeRef on __Type {
 	kind
@@ -0,0 +1,138 @@
+package introspection_test
+
+import (
+	"encoding/json"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDeprecation(t *testing.T) {
+	type Color int
+	type User struct {
+		Name     string `graphql:"name"`
+		Nickname string `graphql:"nickname" deprecated:"use name"`
+		Login    string `graphql:"login" deprecated:""`
+	}
+	type Filter struct {
+		Name  string  `graphql:"name"`
+		Color *string `graphql:"color" deprecated:"use name"`
+	}
+	build := schemabuilder.NewSchema()
+	build.Enum("Color", Color(0), map[string]interface{}{
+		"RED":  Color(0),
+		"BLUE": schemabuilder.DeprecatedField{Field: Color(1), Desc: "blue", Reason: "not a color"},
+	}, "")
+	build.Object("User", User{})
+	build.InputObject("Filter", Filter{})
+	query := build.Query()
+	query.FieldFunc("user", func() *User { return &User{Name: "a", Nickname: "b"} }, "")
+	query.FieldFunc("me", func() *User { return nil }, "", schemabuilder.Deprecated("use user"))
+	query.FieldFunc("users", func(args struct {
+		Filter *Filter `graphql:"filter"`
+		Limit  *int    `graphql:"limit" deprecated:"use first"`
+		First  *int    `graphql:"first"`
+	}) []*User {
+		return nil
+	}, "")
+	query.FieldFunc("color", func() Color { return 0 }, "")
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+
+	do := func(query string) string {
+		res, errs := execution.Do(schema, execution.Params{Query: query})
+		assert.Empty(t, errs)
+		js, err := json.Marshal(res)
+		assert.NoError(t, err)
+		return string(js)
+	}
+
+	assert.JSONEq(t, `{
+	"user": {"fields": [{"name": "name"}]},
+	"query": {"fields": [
+		{"name": "color", "args": []},
+		{"name": "user", "args": []},
+		{"name": "users", "args": [{"name": "filter"}, {"name": "first"}]}
+	]},
+	"color": {"enumValues": [{"name": "RED"}]},
+	"filter": {"inputFields": [{"name": "name"}]}
+}`, do(`{
+	user: __type(name: "User") { fields { name } }
+	query: __type(name: "Query") { fields { name args { name } } }
+	color: __type(name: "Color") { enumValues { name } }
+	filter: __type(name: "Filter") { inputFields { name } }
+}`))
+
+	assert.JSONEq(t, `{
+	"user": {"fields": [
+		{"name": "login", "isDeprecated": true, "deprecationReason": "No longer supported"},
+		{"name": "name", "isDeprecated": false, "deprecationReason": null},
+		{"name": "nickname", "isDeprecated": true, "deprecationReason": "use name"}
+	]},
+	"query": {"fields": [
+		{"name": "color", "isDeprecated": false, "deprecationReason": null, "args": []},
+		{"name": "me", "isDeprecated": true, "deprecationReason": "use user", "args": []},
+		{"name": "user", "isDeprecated": false, "deprecationReason": null, "args": []},
+		{"name": "users", "isDeprecated": false, "deprecationReason": null, "args": [
+			{"name": "filter", "isDeprecated": false, "deprecationReason": null},
+			{"name": "first", "isDeprecated": false, "deprecationReason": null},
+			{"name": "limit", "isDeprecated": true, "deprecationReason": "use first"}
+		]}
+	]},
+	"color": {"enumValues": [
+		{"name": "BLUE", "isDeprecated": true, "deprecationReason": "not a color"},
+		{"name": "RED", "isDeprecated": false, "deprecationReason": null}
+	]},
+	"filter": {"inputFields": [
+		{"name": "color", "isDeprecated": true, "deprecationReason": "use name"},
+		{"name": "name", "isDeprecated": false, "deprecationReason": null}
+	]}
+}`, do(`{
+	user: __type(name: "User") { fields(includeDeprecated: true) { name isDeprecated deprecationReason } }
+	query: __type(name: "Query") {
+		fields(includeDeprecated: true) {
+			name isDeprecated deprecationReason
+			args(includeDeprecated: true) { name isDeprecated deprecationReason }
+		}
+	}
+	color: __type(name: "Color") { enumValues(includeDeprecated: true) { name isDeprecated deprecationReason } }
+	filter: __type(name: "Filter") { inputFields(includeDeprecated: true) { name isDeprecated deprecationReason } }
+}`))
+
+	// deprecated fields still resolve
+	assert.JSONEq(t, `{"user": {"nickname": "b"}}`, do(`{ user { nickname } }`))
+
+	_, err := introspection.ComputeSchemaJSON(schema)
+	assert.NoError(t, err)
+
+	// a required argument can't be deprecated
+	build = schemabuilder.NewSchema()
+	build.Query().FieldFunc("user", func(args struct {
+		ID int `graphql:"id" deprecated:"use name"`
+	}) string {
+		return ""
+	}, "")
+	_, err = build.Build()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "id is required and can not be deprecated")
+	}
+}
+
+func TestDirectiveArgs(t *testing.T) {
+	schema := schemabuilder.MustBuildSDL(`
+directive @cost(weight: Int = 2) on FIELD_DEFINITION
+
+type Query {
+  hello: String @cost
+}
+`, schemabuilder.Resolvers{"Query.hello": func() string { return "world" }})
+	introspection.AddIntrospectionToSchema(schema)
+
+	res, errs := execution.Do(schema, execution.Params{Query: `{ __schema { directives { name args { name defaultValue } } } }`})
+	assert.Empty(t, errs)
+	js, err := json.Marshal(res)
+	assert.NoError(t, err)
+	assert.Contains(t, string(js), `{"name":"cost","args":[{"name":"weight","defaultValue":"2"}]}`)
+}
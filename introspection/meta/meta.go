@@ -0,0 +1,421 @@
+// Package meta defines the types of introspection, for the introspection package to resolve
+// them and the validation package to check the queries selecting them.
+package meta
+
+import (
+	"fmt"
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/schemabuilder"
+	"sort"
+	"strings"
+)
+
+// Register registers the types of introspection in schema, and the __schema and __type fields
+// of its query, which resolve to the values of schemaFn and typeFn.
+func Register(schema *schemabuilder.Schema, schemaFn func() *Schema, typeFn func(name string) *Type) {
+	registerDirective(schema)
+	registerEnumValue(schema)
+	registerField(schema)
+	registerInputValue(schema)
+	registerSchema(schema)
+	registerType(schema)
+
+	object := schema.Query()
+	object.FieldFunc("__schema", schemaFn, "")
+	object.FieldFunc("__type", func(args struct {
+		Name string `graphql:"name"`
+	}) *Type {
+		return typeFn(args.Name)
+	}, "")
+}
+
+// Definitions returns the definitions of the __schema and __type fields of the query root, and
+// of the types of introspection by name, the way Register registers them.
+func Definitions() (map[string]*internal.Field, map[string]internal.NamedType) {
+	schema := schemabuilder.NewSchema()
+	Register(schema, func() *Schema { return nil }, func(string) *Type { return nil })
+	built := schema.MustBuild()
+
+	fields := make(map[string]*internal.Field)
+	for _, name := range []string{"__schema", "__type"} {
+		fields[name] = built.Query.(*internal.Object).Fields[name]
+	}
+	types := make(map[string]internal.NamedType)
+	for name, typ := range built.TypeMap {
+		if strings.HasPrefix(name, "__") {
+			types[name] = typ
+		}
+	}
+	return fields, types
+}
+
+type DirectiveLocation string
+
+const (
+	LocationQuery                DirectiveLocation = "QUERY"
+	LocationMutation             DirectiveLocation = "MUTATION"
+	LocationSubscription         DirectiveLocation = "SUBSCRIPTION"
+	LocationField                DirectiveLocation = "FIELD"
+	LocationFragmentDefinition   DirectiveLocation = "FRAGMENT_DEFINITION"
+	LocationFragmentSpread       DirectiveLocation = "FRAGMENT_SPREAD"
+	LocationInlineFragment       DirectiveLocation = "INLINE_FRAGMENT"
+	LocationSchema               DirectiveLocation = "SCHEMA"
+	LocationScalar               DirectiveLocation = "SCALAR"
+	LocationObject               DirectiveLocation = "OBJECT"
+	LocationFieldDefinition      DirectiveLocation = "FIELD_DEFINITION"
+	LocationArgumentDefinition   DirectiveLocation = "ARGUMENT_DEFINITION"
+	LocationInterface            DirectiveLocation = "INTERFACE"
+	LocationUnion                DirectiveLocation = "UNION"
+	LocationEnum                 DirectiveLocation = "ENUM"
+	LocationEnumValue            DirectiveLocation = "ENUM_VALUE"
+	LocationInputObject          DirectiveLocation = "INPUT_OBJECT"
+	LocationInputFieldDefinition DirectiveLocation = "INPUT_FIELD_DEFINITION"
+)
+
+// There are several different kinds of type. In each kind, different fields are actually valid.
+// These kinds are listed in the __TypeKind enumeration.
+type TypeKind string
+
+const (
+	SCALAR       TypeKind = "SCALAR"
+	OBJECT       TypeKind = "OBJECT"
+	INTERFACE    TypeKind = "INTERFACE"
+	UNION        TypeKind = "UNION"
+	ENUM         TypeKind = "ENUM"
+	INPUT_OBJECT TypeKind = "INPUT_OBJECT"
+	LIST         TypeKind = "LIST"
+	NON_NULL     TypeKind = "NON_NULL"
+)
+
+// The schema introspection system is accessible from the meta‐fields __schema and __type which are accessible
+// from the type of the root of a query operation.
+type Schema struct {
+	Desc             string      `graphql:"description"`
+	Types            []Type      `graphql:"types"`
+	QueryType        *Type       `graphql:"queryType"`
+	MutationType     *Type       `graphql:"mutationType"`
+	SubscriptionType *Type       `graphql:"subscriptionType"`
+	Directives       []Directive `graphql:"directives"`
+}
+
+func registerSchema(schema *schemabuilder.Schema) {
+	schema.Object("__Schema", Schema{}, "")
+}
+
+// __Type is at the core of the type introspection system.
+// It represents scalars, interfaces, object types, unions, enums in the system.
+//
+// __Type also represents type modifiers, which are used to modify a type that it refers to (ofType: __Type).
+// This is how we represent lists, non‐nullable types, and the combinations thereof.
+type Type struct {
+	OfType internal.Type `graphql:"-" json:"-"`
+}
+
+//var includeDirective = __Directive{
+//	Name: "include",
+//	Desc: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+//	Locations: []DirectiveLocation{
+//		Field,
+//		FragmentSpread,
+//		InlineFragment,
+//	},
+//	Args: []__InputValue{
+//		{
+//			Name: "if",
+//			Fn: __Type{OfType: &system.Scalar{Name: "Boolean"}},
+//			Desc: "Included when true.",
+//		},
+//	},
+//	IsDeprecated: false,
+//}
+//
+//var skipDirective = __Directive{
+//	Name: "skip",
+//	Desc: "Directs the executor to skip this field or fragment only when the `if` argument is true.",
+//	Locations: []DirectiveLocation{
+//		Field,
+//		FragmentSpread,
+//		InlineFragment,
+//	},
+//	Args: []__InputValue{
+//		{
+//			Name: "if",
+//			Fn: __Type{OfType: &system.Scalar{Name: "Boolean"}},
+//			Desc: "Skipped when true.",
+//		},
+//	},
+//	IsDeprecated: false,
+//}
+
+func registerType(schema *schemabuilder.Schema) {
+	schema.Enum("__TypeKind", TypeKind(""), map[string]interface{}{
+		string(OBJECT):       OBJECT,
+		string(UNION):        UNION,
+		string(SCALAR):       SCALAR,
+		string(ENUM):         ENUM,
+		string(LIST):         LIST,
+		string(INPUT_OBJECT): INPUT_OBJECT,
+		string(NON_NULL):     NON_NULL,
+		string(INTERFACE):    INTERFACE,
+	}, "")
+	object := schema.Object("__Type", Type{}, "")
+	object.FieldFunc("kind", func(t Type) TypeKind {
+		switch t.OfType.(type) {
+		case *internal.Object:
+			return OBJECT
+		case *internal.Union:
+			return UNION
+		case *internal.Scalar:
+			return SCALAR
+		case *internal.Enum:
+			return ENUM
+		case *internal.List:
+			return LIST
+		case *internal.InputObject:
+			return INPUT_OBJECT
+		case *internal.NonNull:
+			return NON_NULL
+		case *internal.Interface:
+			return INTERFACE
+		}
+		return ""
+	}, "")
+
+	object.FieldFunc("name", func(t Type) string {
+		switch t := t.OfType.(type) {
+		case internal.NamedType:
+			return t.TypeName()
+		default:
+			return ""
+		}
+	}, "")
+
+	object.FieldFunc("description", func(t Type) string {
+		switch t := t.OfType.(type) {
+		case internal.NamedType:
+			return t.Description()
+		default:
+			return ""
+		}
+	}, "")
+
+	object.FieldFunc("fields", func(t Type, args struct {
+		IncludeDeprecated *bool `graphql:"includeDeprecated"`
+	}) []Field {
+		fields := make([]Field, 0)
+
+		var typeFields map[string]*internal.Field
+		switch t := t.OfType.(type) {
+		case *internal.Object:
+			typeFields = t.Fields
+		case *internal.Interface:
+			typeFields = t.Fields
+		}
+		for name, field := range typeFields {
+			if field.DeprecationReason != nil && !includeDeprecated(args.IncludeDeprecated) {
+				continue
+			}
+			fields = append(fields, Field{
+				Name:              name,
+				Desc:              &field.Desc,
+				Type:              Type{OfType: field.Type},
+				IsDeprecated:      field.DeprecationReason != nil,
+				DeprecationReason: field.DeprecationReason,
+				args:              field.Args,
+			})
+		}
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+
+		return fields
+	}, "should be non-null for OBJECT and INTERFACE only, must be null for the others")
+
+	object.FieldFunc("interfaces", func(t Type) []Type {
+		interfaces := make([]Type, 0)
+
+		switch t := t.OfType.(type) {
+		case *internal.Object:
+			for _, i := range t.Interfaces {
+				interfaces = append(interfaces, Type{OfType: i})
+			}
+		case *internal.Interface:
+			for _, i := range t.Interfaces {
+				interfaces = append(interfaces, Type{OfType: i})
+			}
+		}
+		sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].OfType.String() < interfaces[j].OfType.String() })
+
+		return interfaces
+	}, "should be non-null for OBJECT and INTERFACE only, must be null for the others")
+
+	object.FieldFunc("possibleTypes", func(t Type) []Type {
+		types := make([]Type, 0)
+
+		switch t := t.OfType.(type) {
+		case *internal.Union:
+			for _, typ := range t.Types {
+				types = append(types, Type{OfType: typ})
+			}
+		case *internal.Interface:
+			for _, typ := range t.PossibleTypes {
+				types = append(types, Type{OfType: typ})
+			}
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i].OfType.String() < types[j].OfType.String() })
+		return types
+	}, "should be non-null for INTERFACE and UNION only, always null for the others")
+
+	object.FieldFunc("enumValues", func(t Type, args struct {
+		IncludeDeprecated *bool `graphql:"includeDeprecated"`
+	}) []EnumValue {
+
+		switch t := t.OfType.(type) {
+		case *internal.Enum:
+			enumValues := make([]EnumValue, 0)
+			for _, v := range t.Map {
+				desc := t.ValuesDesc[v]
+				var reason *string
+				if r, ok := t.DeprecatedValues[v]; ok {
+					if !includeDeprecated(args.IncludeDeprecated) {
+						continue
+					}
+					reason = &r
+				}
+				enumValues = append(enumValues,
+					EnumValue{Name: v, Desc: &desc, IsDeprecated: reason != nil, DeprecationReason: reason})
+			}
+			sort.Slice(enumValues, func(i, j int) bool { return enumValues[i].Name < enumValues[j].Name })
+			return enumValues
+		}
+		return []EnumValue{}
+	}, "should be non-null for ENUM only, must be null for the others")
+
+	object.FieldFunc("inputFields", func(t Type, args struct {
+		IncludeDeprecated *bool `graphql:"includeDeprecated"`
+	}) []InputValue {
+		switch t := t.OfType.(type) {
+		case *internal.InputObject:
+			return inputValues(t.Fields, includeDeprecated(args.IncludeDeprecated))
+		}
+		return []InputValue{}
+	}, "should be non-null for INPUT_OBJECT only, must be null for the others")
+
+	object.FieldFunc("ofType", func(t Type) *Type {
+		switch t := t.OfType.(type) {
+		case *internal.List:
+			return &Type{OfType: t.Type}
+		case *internal.NonNull:
+			return &Type{OfType: t.Type}
+		default:
+			return nil
+		}
+	}, "should be non-null for NON_NULL and LIST only, must be null for the others")
+}
+
+// includeDeprecated is the value of the includeDeprecated argument, which defaults to false.
+func includeDeprecated(arg *bool) bool {
+	return arg != nil && *arg
+}
+
+// The __Field type represents each field in an Object or Interface type.
+type Field struct {
+	Name              string  `graphql:"name"`
+	Desc              *string `graphql:"description"`
+	Type              Type    `graphql:"type"`
+	IsDeprecated      bool    `graphql:"isDeprecated"`
+	DeprecationReason *string `graphql:"deprecationReason"`
+	args              map[string]*internal.InputField
+}
+
+func registerField(schema *schemabuilder.Schema) {
+	object := schema.Object("__Field", Field{}, "")
+	object.FieldFunc("args", func(f Field, args struct {
+		IncludeDeprecated *bool `graphql:"includeDeprecated"`
+	}) []InputValue {
+		return inputValues(f.args, includeDeprecated(args.IncludeDeprecated))
+	}, "")
+}
+
+// The __InputValue type represents field and directive arguments as well as the inputFields of an input object.
+type InputValue struct {
+	Name              string  `graphql:"name"`
+	Desc              string  `graphql:"description"`
+	Type              Type    `graphql:"type"`
+	DefaultValue      *string `graphql:"defaultValue"`
+	IsDeprecated      bool    `graphql:"isDeprecated"`
+	DeprecationReason *string `graphql:"deprecationReason"`
+}
+
+// inputValues returns the arguments or input fields of fields sorted by name,
+// without the deprecated ones unless deprecated is set.
+func inputValues(fields map[string]*internal.InputField, deprecated bool) []InputValue {
+	values := make([]InputValue, 0, len(fields))
+	for name, f := range fields {
+		if f.DeprecationReason != nil && !deprecated {
+			continue
+		}
+		var defaultValue string
+		if f.DefaultValue != nil {
+			defaultValue = fmt.Sprintf("%v", f.DefaultValue)
+		}
+		values = append(values, InputValue{
+			Name:              name,
+			Desc:              f.Desc,
+			Type:              Type{OfType: f.Type},
+			DefaultValue:      &defaultValue,
+			IsDeprecated:      f.DeprecationReason != nil,
+			DeprecationReason: f.DeprecationReason,
+		})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+func registerInputValue(schema *schemabuilder.Schema) {
+	schema.Object("__InputValue", InputValue{}, "")
+}
+
+// The __EnumValue type represents one of possible values of an enum.
+type EnumValue struct {
+	Name              string  `graphql:"name"`
+	Desc              *string `graphql:"description"`
+	IsDeprecated      bool    `graphql:"isDeprecated"`
+	DeprecationReason *string `graphql:"deprecationReason"`
+}
+
+func registerEnumValue(schema *schemabuilder.Schema) {
+	schema.Object("__EnumValue", EnumValue{}, "")
+}
+
+// The __Directive type represents a Directive that a server supports.
+type Directive struct {
+	Name         string              `graphql:"name"`
+	Desc         string              `graphql:"description"`
+	Locations    []DirectiveLocation `graphql:"locations"`
+	Args         []InputValue        `graphql:"args"`
+	IsDeprecated bool                `graphql:"isDeprecated"`
+	IsRepeatable bool                `graphql:"isRepeatable"`
+}
+
+func registerDirective(schema *schemabuilder.Schema) {
+	schema.Object("__Directive", Directive{}, "")
+	schema.Enum("__DirectiveLocation", DirectiveLocation("QUERY"), map[string]DirectiveLocation{
+		"QUERY":                  LocationQuery,
+		"MUTATION":               LocationMutation,
+		"FIELD":                  LocationField,
+		"FRAGMENT_DEFINITION":    LocationFragmentDefinition,
+		"FRAGMENT_SPREAD":        LocationFragmentSpread,
+		"INLINE_FRAGMENT":        LocationInlineFragment,
+		"SUBSCRIPTION":           LocationSubscription,
+		"SCHEMA":                 LocationSchema,
+		"SCALAR":                 LocationScalar,
+		"OBJECT":                 LocationObject,
+		"FIELD_DEFINITION":       LocationFieldDefinition,
+		"ARGUMENT_DEFINITION":    LocationArgumentDefinition,
+		"INTERFACE":              LocationInterface,
+		"UNION":                  LocationUnion,
+		"ENUM":                   LocationEnum,
+		"ENUM_VALUE":             LocationEnumValue,
+		"INPUT_OBJECT":           LocationInputObject,
+		"INPUT_FIELD_DEFINITION": LocationInputFieldDefinition,
+	}, "")
+}
@@ -0,0 +1,35 @@
+package meta_test
+
+import (
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/introspection/meta"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDefinitions(t *testing.T) {
+	fields, types := meta.Definitions()
+	assert.Equal(t, "__Schema", fields["__schema"].Type.String())
+	assert.Equal(t, "__Type", fields["__type"].Type.String())
+	assert.Equal(t, "String!", fields["__type"].Args["name"].Type.String())
+
+	// the definitions are those AddIntrospectionToSchema adds
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("a", func() string { return "a" }, "")
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+	var names []string
+	for name, typ := range schema.TypeMap {
+		if _, ok := types[name]; ok {
+			names = append(names, name)
+			assert.IsType(t, typ, types[name])
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"__Schema", "__Type", "__Field", "__InputValue", "__EnumValue", "__Directive", "__TypeKind", "__DirectiveLocation",
+	}, names)
+	assert.Len(t, types, len(names))
+	assert.Contains(t, types["__Type"].(*internal.Object).Fields, "possibleTypes")
+}
@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"log"
+	"time"
+)
+
+// Option configures a Handler built by HTTPHandler.
+type Option func(h *Handler)
+
+// config returns the settings of the handler, those of Ctx when it has none of its own.
+func (h *Handler) config() *Context {
+	if h.settings != nil {
+		return h.settings
+	}
+	return Ctx
+}
+
+// WithMiddleware appends mm to the middleware chain of the handler.
+func WithMiddleware(mm ...HandlerFunc) Option {
+	return func(h *Handler) {
+		h.settings.HandlersChain = append(h.settings.HandlersChain, mm...)
+	}
+}
+
+// WithLogger sets the logger of the handler.
+func WithLogger(logger *log.Logger) Option {
+	return func(h *Handler) {
+		h.settings.Logger = logger
+	}
+}
+
+// WithMaxDepth is the handler's MaxDepth.
+func WithMaxDepth(n int) Option {
+	return func(h *Handler) {
+		h.settings.MaxDepth = n
+	}
+}
+
+// WithMaxFields is the handler's MaxFields.
+func WithMaxFields(n int) Option {
+	return func(h *Handler) {
+		h.settings.MaxFields = n
+	}
+}
+
+// WithMaxAliases is the handler's MaxAliases.
+func WithMaxAliases(n int) Option {
+	return func(h *Handler) {
+		h.settings.MaxAliases = n
+	}
+}
+
+// WithMaxDirectives is the handler's MaxDirectives.
+func WithMaxDirectives(n int) Option {
+	return func(h *Handler) {
+		h.settings.MaxDirectives = n
+	}
+}
+
+// WithMaxBatchSize is the handler's MaxBatchSize.
+func WithMaxBatchSize(n int) Option {
+	return func(h *Handler) {
+		h.settings.MaxBatchSize = n
+	}
+}
+
+// WithMaxUploadSize is the handler's MaxUploadSize.
+func WithMaxUploadSize(n int64) Option {
+	return func(h *Handler) {
+		h.settings.MaxUploadSize = n
+	}
+}
+
+// WithMaxUploads is the handler's MaxUploads.
+func WithMaxUploads(n int) Option {
+	return func(h *Handler) {
+		h.settings.MaxUploads = n
+	}
+}
+
+// WithTimeout is the handler's Timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.settings.Timeout = d
+	}
+}
+
+// WithCache sets the Cache of the handler.
+func WithCache(cache *QueryCache) Option {
+	return func(h *Handler) {
+		h.Cache = cache
+	}
+}
+
+// WithPersistedQueries sets the PersistedQueries of the handler.
+func WithPersistedQueries(store PersistedQueryStore) Option {
+	return func(h *Handler) {
+		h.PersistedQueries = store
+	}
+}
+
+// WithMaxCost sets the MaxCost of the handler.
+func WithMaxCost(n int) Option {
+	return func(h *Handler) {
+		h.MaxCost = n
+	}
+}
+
+// WithTracing enables the Tracing of the handler.
+func WithTracing() Option {
+	return func(h *Handler) {
+		h.Tracing = true
+	}
+}
+
+// WithErrorPresenter sets the ErrorPresenter of the handler.
+func WithErrorPresenter(presenter ErrorPresenter) Option {
+	return func(h *Handler) {
+		h.ErrorPresenter = presenter
+	}
+}
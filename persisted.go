@@ -0,0 +1,83 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/execution"
+	"strings"
+)
+
+// PersistedQueryStore keeps the queries of automatic persisted queries by their sha256 hash.
+//
+// Clients first send only the hash of a query in extensions.persistedQuery. When the
+// hash is unknown, they get a PersistedQueryNotFound error and send the full query along
+// with the hash, which registers it for the next requests.
+type PersistedQueryStore interface {
+	Get(ctx context.Context, hash string) (query string, ok bool)
+	Set(ctx context.Context, hash, query string)
+}
+
+type memoryPersistedQueryStore struct {
+	lru *lru
+}
+
+// NewMemoryPersistedQueryStore returns a PersistedQueryStore keeping at most size queries in memory.
+func NewMemoryPersistedQueryStore(size int) PersistedQueryStore {
+	return &memoryPersistedQueryStore{lru: newLRU(size)}
+}
+
+func (s *memoryPersistedQueryStore) Get(ctx context.Context, hash string) (string, bool) {
+	query, ok := s.lru.get(hash)
+	if !ok {
+		return "", false
+	}
+	return query.(string), true
+}
+
+func (s *memoryPersistedQueryStore) Set(ctx context.Context, hash, query string) {
+	s.lru.add(hash, query)
+}
+
+// persistedQuery fills in the query of param from its persisted query hash, or
+// registers the query under its hash when both are present.
+func (h *Handler) persistedQuery(ctx context.Context, param *execution.Params) *errors.GraphQLError {
+	ext, ok := param.Extensions["persistedQuery"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if h.PersistedQueries == nil {
+		return &errors.GraphQLError{
+			Message:    "PersistedQueryNotSupported",
+			Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_SUPPORTED"},
+		}
+	}
+	if version, _ := ext["version"].(float64); version != 1 {
+		return errors.New("unsupported persisted query version %v", ext["version"])
+	}
+	hash, _ := ext["sha256Hash"].(string)
+	if hash == "" {
+		return errors.New("persisted query must have a sha256Hash")
+	}
+	hash = strings.ToLower(hash)
+
+	if param.Query == "" {
+		query, ok := h.PersistedQueries.Get(ctx, hash)
+		if !ok {
+			return &errors.GraphQLError{
+				Message:    "PersistedQueryNotFound",
+				Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"},
+			}
+		}
+		param.Query = query
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(param.Query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return errors.New("provided sha256Hash does not match query")
+	}
+	h.PersistedQueries.Set(ctx, hash, param.Query)
+	return nil
+}
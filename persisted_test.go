@@ -0,0 +1,63 @@
+package graphql_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/shyptr/graphql"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPersistedQueries(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Query().FieldFunc("hello", func() string { return "world" }, "")
+	schema := build.MustBuild()
+	handler := &graphql.Handler{
+		Schema:           schema,
+		Executor:         &execution.Executor{},
+		PersistedQueries: graphql.NewMemoryPersistedQueryStore(10),
+	}
+
+	const query = "{ hello }"
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+	extensions := fmt.Sprintf(`{"persistedQuery": {"version": 1, "sha256Hash": "%s"}}`, hash)
+
+	post := func(h *graphql.Handler, body string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		res, err := ioutil.ReadAll(w.Body)
+		assert.NoError(t, err)
+		return string(res)
+	}
+	get := func(values url.Values) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/?"+values.Encode(), nil))
+		res, err := ioutil.ReadAll(w.Body)
+		assert.NoError(t, err)
+		return string(res)
+	}
+
+	notFound := `{"errors": [{"message": "PersistedQueryNotFound", "extensions": {"code": "PERSISTED_QUERY_NOT_FOUND"}}]}`
+	assert.JSONEq(t, notFound, post(handler, fmt.Sprintf(`{"extensions": %s}`, extensions)))
+	assert.JSONEq(t, notFound, get(url.Values{"extensions": {extensions}}))
+
+	// sending the query along with its hash registers it
+	assert.JSONEq(t, `{"data": {"hello": "world"}}`, post(handler, fmt.Sprintf(`{"query": "%s", "extensions": %s}`, query, extensions)))
+	assert.JSONEq(t, `{"data": {"hello": "world"}}`, post(handler, fmt.Sprintf(`{"extensions": %s}`, extensions)))
+	assert.JSONEq(t, `{"data": {"hello": "world"}}`, get(url.Values{"extensions": {extensions}}))
+
+	assert.JSONEq(t, `{"errors": [{"message": "provided sha256Hash does not match query"}]}`,
+		post(handler, fmt.Sprintf(`{"query": "{ hello __typename }", "extensions": %s}`, extensions)))
+
+	unsupported := &graphql.Handler{Schema: schema, Executor: &execution.Executor{}}
+	assert.JSONEq(t, `{"errors": [{"message": "PersistedQueryNotSupported", "extensions": {"code": "PERSISTED_QUERY_NOT_SUPPORTED"}}]}`,
+		post(unsupported, fmt.Sprintf(`{"extensions": %s}`, extensions)))
+}
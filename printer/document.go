@@ -0,0 +1,151 @@
+package printer
+
+import (
+	"encoding/json"
+	"github.com/shyptr/graphql/ast"
+	"strings"
+)
+
+// PrintDocument prints the operations and fragments of doc, in their order, with one selection
+// per line indented by two spaces. Comments and commas are not kept, and type system definitions
+// are left out.
+func PrintDocument(doc *ast.Document) string {
+	var defs []string
+	for _, definition := range doc.Definition {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			defs = append(defs, printOperation(definition))
+		case *ast.FragmentDefinition:
+			defs = append(defs, printFragment(definition))
+		}
+	}
+	if len(defs) == 0 {
+		return ""
+	}
+	return strings.Join(defs, "\n\n") + "\n"
+}
+
+// printOperation prints an operation, using the shorthand for queries which have
+// nothing but their selections.
+func printOperation(operation *ast.OperationDefinition) string {
+	if operation.Operation == ast.Query && operation.Name == nil && len(operation.Vars) == 0 && len(operation.Directives) == 0 {
+		return printSelectionSet(operation.SelectionSet, "")
+	}
+	s := strings.ToLower(string(operation.Operation))
+	if operation.Name != nil {
+		s += " " + operation.Name.Name
+	}
+	if len(operation.Vars) > 0 {
+		vars := make([]string, len(operation.Vars))
+		for i, v := range operation.Vars {
+			vars[i] = "$" + v.Var.Name.Name + ": " + v.Type.String()
+			if v.DefaultValue != nil {
+				vars[i] += " = " + printLiteral(v.DefaultValue)
+			}
+			vars[i] += printDirectives(v.Directives)
+		}
+		s += "(" + strings.Join(vars, ", ") + ")"
+	}
+	return s + printDirectives(operation.Directives) + " " + printSelectionSet(operation.SelectionSet, "")
+}
+
+func printFragment(fragment *ast.FragmentDefinition) string {
+	return "fragment " + fragment.Name.Name + " on " + fragment.TypeCondition.String() +
+		printDirectives(fragment.Directives) + " " + printSelectionSet(fragment.SelectionSet, "")
+}
+
+// printSelectionSet prints the selections of a selection set at the depth of indent.
+func printSelectionSet(selectionSet *ast.SelectionSet, indent string) string {
+	if selectionSet == nil || len(selectionSet.Selections) == 0 {
+		return "{}"
+	}
+	lines := make([]string, len(selectionSet.Selections))
+	for i, selection := range selectionSet.Selections {
+		lines[i] = indent + "  " + printSelection(selection, indent+"  ")
+	}
+	return "{\n" + strings.Join(lines, "\n") + "\n" + indent + "}"
+}
+
+func printSelection(selection ast.Selection, indent string) string {
+	switch selection := selection.(type) {
+	case *ast.Field:
+		var s string
+		if selection.Alias != nil && selection.Alias.Name != selection.Name.Name {
+			s = selection.Alias.Name + ": "
+		}
+		s += selection.Name.Name + printArguments(selection.Arguments) + printDirectives(selection.Directives)
+		if selection.SelectionSet != nil {
+			s += " " + printSelectionSet(selection.SelectionSet, indent)
+		}
+		return s
+	case *ast.FragmentSpread:
+		return "..." + selection.Name.Name + printDirectives(selection.Directives)
+	case *ast.InlineFragment:
+		s := "..."
+		if selection.TypeCondition != nil {
+			s += " on " + selection.TypeCondition.String()
+		}
+		return s + printDirectives(selection.Directives) + " " + printSelectionSet(selection.SelectionSet, indent)
+	}
+	return ""
+}
+
+func printArguments(args []*ast.Argument) string {
+	if len(args) == 0 {
+		return ""
+	}
+	printed := make([]string, len(args))
+	for i, arg := range args {
+		printed[i] = arg.Name.Name + ": " + printLiteral(arg.Value)
+	}
+	return "(" + strings.Join(printed, ", ") + ")"
+}
+
+func printDirectives(directives []*ast.Directive) string {
+	var s string
+	for _, directive := range directives {
+		s += " @" + directive.Name.Name + printArguments(directive.Args)
+	}
+	return s
+}
+
+// printLiteral prints a value as it is written in a document, unlike printValue
+// which prints the Go value of a default.
+func printLiteral(value ast.Value) string {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return "$" + value.Name.Name
+	case *ast.IntValue:
+		return value.Value
+	case *ast.FloatValue:
+		return value.Value
+	case *ast.StringValue:
+		// the parser keeps the escape sequences of strings, only block strings are unescaped
+		if quoted := `"` + value.Value + `"`; json.Valid([]byte(quoted)) {
+			return quoted
+		}
+		return printString(value.Value)
+	case *ast.BooleanValue:
+		if value.Value {
+			return "true"
+		}
+		return "false"
+	case *ast.NullValue:
+		return "null"
+	case *ast.EnumValue:
+		return value.Value
+	case *ast.ListValue:
+		items := make([]string, len(value.Values))
+		for i, item := range value.Values {
+			items[i] = printLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *ast.ObjectValue:
+		fields := make([]string, len(value.Fields))
+		for i, field := range value.Fields {
+			fields[i] = field.Name.Name.Name + ": " + printLiteral(field.Value)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package printer_test
+
+import (
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/printer"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPrintDocument(t *testing.T) {
+	doc, err := internal.ParseDocument(`
+# the pets of an owner
+query Pets($owner: ID!, $colors: [Color!] = [RED, BLUE], $first: Int = 10) @cached(ttl: 60) {
+  owner(id: $owner) { name,
+    pets(filter: {colors: $colors, name: "Rex \"the\" dog", limit: null}, first: $first) {
+      __typename ...pet
+      ... on Dog @include(if: true) { barks nick: nickname }
+      ... @skip(if: false) { name }
+    }
+  }
+}
+fragment pet on Pet { name }
+{ owner(id: 1.5) { name: name } }
+mutation { adopt(pet: "Rex", adopted: true) { name } }
+`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	expected := `query Pets($owner: ID!, $colors: [Color!] = [RED, BLUE], $first: Int = 10) @cached(ttl: 60) {
+  owner(id: $owner) {
+    name
+    pets(filter: {colors: $colors, name: "Rex \"the\" dog", limit: null}, first: $first) {
+      __typename
+      ...pet
+      ... on Dog @include(if: true) {
+        barks
+        nick: nickname
+      }
+      ... @skip(if: false) {
+        name
+      }
+    }
+  }
+}
+
+fragment pet on Pet {
+  name
+}
+
+{
+  owner(id: 1.5) {
+    name
+  }
+}
+
+mutation {
+  adopt(pet: "Rex", adopted: true) {
+    name
+  }
+}
+`
+	printed := printer.PrintDocument(doc)
+	assert.Equal(t, expected, printed)
+
+	// printing is stable
+	reparsed, err := internal.ParseDocument(printed)
+	if assert.Nil(t, err) {
+		assert.Equal(t, expected, printer.PrintDocument(reparsed))
+	}
+}
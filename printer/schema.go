@@ -0,0 +1,403 @@
+// Package printer prints schemas and documents in the GraphQL language.
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shyptr/graphql/internal"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var specifiedScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+// specifiedDirectives are the directives every schema has, they are not printed.
+var specifiedDirectives = map[string]bool{
+	"include": true, "skip": true, "deprecated": true, "specifiedBy": true, "defer": true, "stream": true,
+}
+
+// PrintSchema prints schema in the schema definition language. The directives and the types
+// are sorted by name, as are their fields, arguments and values, so that the same schema always
+// prints the same way. The built-in scalars and directives and the introspection types are left out.
+func PrintSchema(schema *internal.Schema) string {
+	var defs []string
+	if def := printSchemaDefinition(schema); def != "" {
+		defs = append(defs, def)
+	}
+
+	var directives []string
+	for name := range schema.Directives {
+		if !specifiedDirectives[name] {
+			directives = append(directives, name)
+		}
+	}
+	sort.Strings(directives)
+	for _, name := range directives {
+		defs = append(defs, printDirective(schema.Directives[name]))
+	}
+
+	reachable := make(map[string]internal.NamedType)
+	for _, root := range []internal.Type{schema.Query, schema.Mutation, schema.Subscription} {
+		collectTypes(root, reachable)
+	}
+	for _, directive := range schema.Directives {
+		for _, arg := range directive.Args {
+			collectTypes(arg.Type, reachable)
+		}
+	}
+	var types []string
+	for name := range reachable {
+		if !specifiedScalars[name] {
+			types = append(types, name)
+		}
+	}
+	sort.Strings(types)
+	for _, name := range types {
+		defs = append(defs, printType(reachable[name]))
+	}
+	return strings.Join(defs, "\n\n") + "\n"
+}
+
+// printSchemaDefinition prints the schema definition, which is only needed when
+// the root types aren't named after their operations.
+func printSchemaDefinition(schema *internal.Schema) string {
+	roots := []struct {
+		operation string
+		typ       internal.Type
+	}{
+		{"query", schema.Query},
+		{"mutation", schema.Mutation},
+		{"subscription", schema.Subscription},
+	}
+	var conventional = true
+	var lines []string
+	for _, root := range roots {
+		named, ok := root.typ.(internal.NamedType)
+		if !ok || reflect.ValueOf(named).IsNil() {
+			continue
+		}
+		if named.TypeName() != strings.Title(root.operation) {
+			conventional = false
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", root.operation, named.TypeName()))
+	}
+	if conventional {
+		return ""
+	}
+	return "schema {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+// collectTypes adds typ and the types reachable from it to types. The introspection fields
+// are not followed, the schema's TypeMap can't be used as it holds the types of introspection.
+func collectTypes(typ internal.Type, types map[string]internal.NamedType) {
+	switch typ := typ.(type) {
+	case *internal.NonNull:
+		collectTypes(typ.Type, types)
+		return
+	case *internal.List:
+		collectTypes(typ.Type, types)
+		return
+	}
+	named, ok := typ.(internal.NamedType)
+	if !ok || reflect.ValueOf(named).IsNil() {
+		return
+	}
+	if _, ok := types[named.TypeName()]; ok {
+		return
+	}
+	types[named.TypeName()] = named
+
+	collectFields := func(fields map[string]*internal.Field) {
+		for name, field := range fields {
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			collectTypes(field.Type, types)
+			for _, arg := range field.Args {
+				collectTypes(arg.Type, types)
+			}
+		}
+	}
+	switch typ := named.(type) {
+	case *internal.Object:
+		collectFields(typ.Fields)
+		for _, iface := range typ.Interfaces {
+			collectTypes(iface, types)
+		}
+	case *internal.Interface:
+		collectFields(typ.Fields)
+		for _, iface := range typ.Interfaces {
+			collectTypes(iface, types)
+		}
+		for _, object := range typ.PossibleTypes {
+			collectTypes(object, types)
+		}
+	case *internal.Union:
+		for _, object := range typ.Types {
+			collectTypes(object, types)
+		}
+	case *internal.InputObject:
+		for _, field := range typ.Fields {
+			collectTypes(field.Type, types)
+		}
+	}
+}
+
+func printType(typ internal.NamedType) string {
+	switch typ := typ.(type) {
+	case *internal.Scalar:
+		return printDescription(typ.Desc, "") + "scalar " + typ.Name
+	case *internal.Object:
+		return printDescription(typ.Desc, "") + "type " + typ.Name + printImplements(typ.Interfaces) + printFields(typ.Fields)
+	case *internal.Interface:
+		return printDescription(typ.Desc, "") + "interface " + typ.Name + printImplements(typ.Interfaces) + printFields(typ.Fields)
+	case *internal.Union:
+		names := make([]string, 0, len(typ.Types))
+		for name := range typ.Types {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var members string
+		if len(names) > 0 {
+			members = " = " + strings.Join(names, " | ")
+		}
+		return printDescription(typ.Desc, "") + "union " + typ.Name + members
+	case *internal.Enum:
+		return printDescription(typ.Desc, "") + "enum " + typ.Name + printEnumValues(typ)
+	case *internal.InputObject:
+		return printDescription(typ.Desc, "") + "input " + typ.Name + printInputFields(typ.Fields)
+	}
+	return ""
+}
+
+func printImplements(interfaces map[string]*internal.Interface) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(interfaces))
+	for name := range interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return " implements " + strings.Join(names, " & ")
+}
+
+func printFields(fields map[string]*internal.Field) string {
+	var names []string
+	for name := range fields {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		field := fields[name]
+		lines[i] = printDescription(field.Desc, "  ") + "  " + name + printArgs(field.Args, "  ") + ": " +
+			field.Type.String() + printDeprecated(field.DeprecationReason)
+	}
+	return printBlock(lines)
+}
+
+// printArgs prints the arguments of a field or directive, one per line when any of them has a description.
+func printArgs(args map[string]*internal.InputField, indent string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(args))
+	described := false
+	for name, arg := range args {
+		names = append(names, name)
+		described = described || arg.Desc != ""
+	}
+	sort.Strings(names)
+	printed := make([]string, len(names))
+	for i, name := range names {
+		printed[i] = printInputValue(args[name])
+	}
+	if !described {
+		return "(" + strings.Join(printed, ", ") + ")"
+	}
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = printDescription(args[name].Desc, indent+"  ") + indent + "  " + printed[i]
+	}
+	return "(\n" + strings.Join(lines, "\n") + "\n" + indent + ")"
+}
+
+func printInputFields(fields map[string]*internal.InputField) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = printDescription(fields[name].Desc, "  ") + "  " + printInputValue(fields[name])
+	}
+	return printBlock(lines)
+}
+
+func printInputValue(value *internal.InputField) string {
+	s := value.Name + ": " + value.Type.String()
+	if value.DefaultValue != nil {
+		s += " = " + printValue(value.DefaultValue, value.Type)
+	}
+	return s + printDeprecated(value.DeprecationReason)
+}
+
+func printEnumValues(enum *internal.Enum) string {
+	names := append([]string(nil), enum.Values...)
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		var reason *string
+		if r, ok := enum.DeprecatedValues[name]; ok {
+			reason = &r
+		}
+		lines[i] = printDescription(enum.ValuesDesc[name], "  ") + "  " + name + printDeprecated(reason)
+	}
+	return printBlock(lines)
+}
+
+func printDirective(directive *internal.Directive) string {
+	var repeatable string
+	if directive.Repeatable {
+		repeatable = " repeatable"
+	}
+	return printDescription(directive.Desc, "") + "directive @" + directive.Name + printArgs(directive.Args, "") +
+		repeatable + " on " + strings.Join(directive.Locs, " | ")
+}
+
+func printDeprecated(reason *string) string {
+	if reason == nil {
+		return ""
+	}
+	if *reason == internal.DefaultDeprecationReason {
+		return " @deprecated"
+	}
+	return " @deprecated(reason: " + printString(*reason) + ")"
+}
+
+func printBlock(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return " {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+// printDescription prints desc as a block string before a definition indented by indent.
+// Long descriptions and those of many lines have their quotes on lines of their own.
+func printDescription(desc, indent string) string {
+	if desc == "" {
+		return ""
+	}
+	escaped := strings.Replace(desc, `"""`, `\"""`, -1)
+	if !strings.Contains(desc, "\n") && len(desc) <= 70 && !strings.HasSuffix(desc, `"`) && !strings.HasSuffix(desc, `\`) {
+		return indent + `"""` + escaped + `"""` + "\n"
+	}
+	lines := strings.Split(escaped, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return indent + `"""` + "\n" + strings.Join(lines, "\n") + "\n" + indent + `"""` + "\n"
+}
+
+func printString(s string) string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// printValue prints value, the Go value of a default value of type typ, as a GraphQL literal.
+func printValue(value interface{}, typ internal.Type) string {
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		typ = nonNull.Type
+	}
+	rv := reflect.ValueOf(value)
+	for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return "null"
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "null"
+	}
+	value = rv.Interface()
+
+	switch typ := typ.(type) {
+	case *internal.Enum:
+		if name, ok := typ.Map[value]; ok {
+			return name
+		}
+		if name, ok := value.(string); ok {
+			return name
+		}
+	case *internal.List:
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			items := make([]string, rv.Len())
+			for i := range items {
+				items[i] = printValue(rv.Index(i).Interface(), typ.Type)
+			}
+			return "[" + strings.Join(items, ", ") + "]"
+		}
+		return printValue(value, typ.Type)
+	case *internal.InputObject:
+		if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+			keys := make([]string, 0, rv.Len())
+			for _, key := range rv.MapKeys() {
+				keys = append(keys, key.String())
+			}
+			sort.Strings(keys)
+			fields := make([]string, len(keys))
+			for i, key := range keys {
+				var fieldType internal.Type
+				if field, ok := typ.Fields[key]; ok {
+					fieldType = field.Type
+				}
+				fields[i] = key + ": " + printValue(rv.MapIndex(reflect.ValueOf(key)).Interface(), fieldType)
+			}
+			return "{" + strings.Join(fields, ", ") + "}"
+		}
+	}
+
+	js, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(js, &decoded); err != nil {
+		return string(js)
+	}
+	return printJSON(decoded)
+}
+
+// printJSON prints a decoded JSON value as a GraphQL literal.
+func printJSON(value interface{}) string {
+	switch value := value.(type) {
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = printJSON(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key + ": " + printJSON(value[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case nil:
+		return "null"
+	}
+	js, _ := json.Marshal(value)
+	return string(js)
+}
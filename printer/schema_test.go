@@ -0,0 +1,146 @@
+package printer_test
+
+import (
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/printer"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Pet interface {
+	GetName() string
+}
+
+type Dog struct {
+	Name     string `graphql:"name;The name of the dog."`
+	Nickname string `graphql:"nickname" deprecated:"use name"`
+	Barks    bool   `graphql:"barks" deprecated:""`
+}
+
+func (d Dog) GetName() string { return d.Name }
+
+type Cat struct {
+	Name string `graphql:"name"`
+}
+
+func (c Cat) GetName() string { return c.Name }
+
+type CatOrDog struct {
+	*Cat
+	*Dog
+}
+
+type Color int
+
+type Filter struct {
+	Name   string  `graphql:"name"`
+	Colors []Color `graphql:"colors"`
+	Limit  *int    `graphql:"limit" deprecated:"use first"`
+}
+
+func TestPrintSchema(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Enum("Color", Color(0), map[string]interface{}{
+		"RED":   Color(0),
+		"GREEN": schemabuilder.DescField{Field: Color(1), Desc: "Not red."},
+		"BLUE":  schemabuilder.DeprecatedField{Field: Color(2), Reason: schemabuilder.DefaultDeprecationReason},
+	}, "A color of the \"\"\"rainbow\"\"\".")
+	filter := build.InputObject("Filter", Filter{}, "How to find pets.\nEvery field is optional.")
+	filter.FieldDefault("colors", []Color{0, 2})
+	filter.FieldDefault("limit", 10)
+
+	pet := build.Interface("Pet", new(Pet), nil, "")
+	pet.FieldFunc("name", "GetName", "")
+	build.Object("Dog", Dog{}).InterfaceList(pet)
+	build.Object("Cat", Cat{}).InterfaceList(pet)
+	build.Union("CatOrDog", CatOrDog{}, "")
+
+	query := build.Query()
+	query.FieldFunc("pets", func(args struct {
+		Filter *Filter `graphql:"filter"`
+		First  *int    `graphql:"first"`
+	}) []Pet {
+		return nil
+	}, "Finds the pets matching a filter, this description is long enough to be split.")
+	query.FieldFunc("catOrDog", func(args struct {
+		Name string `graphql:"name;The name of the pet."`
+	}) *CatOrDog {
+		return nil
+	}, "", schemabuilder.Deprecated("use pets"))
+	build.Directive("upper", []string{"FIELD", "FRAGMENT_SPREAD"}, func(args struct {
+		If bool `graphql:"if"`
+	}) (interface{}, error) {
+		return nil, nil
+	}, "Upper cases the result.")
+
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+
+	assert.Equal(t, `"""Upper cases the result."""
+directive @upper(if: Boolean!) on FIELD | FRAGMENT_SPREAD
+
+type Cat implements Pet {
+  name: String!
+}
+
+union CatOrDog = Cat | Dog
+
+"""A color of the \"""rainbow\"""."""
+enum Color {
+  BLUE @deprecated
+  """Not red."""
+  GREEN
+  RED
+}
+
+type Dog implements Pet {
+  barks: Boolean! @deprecated
+  """The name of the dog."""
+  name: String!
+  nickname: String! @deprecated(reason: "use name")
+}
+
+"""
+How to find pets.
+Every field is optional.
+"""
+input Filter {
+  colors: [Color!] = [RED, BLUE]
+  limit: Int = 10 @deprecated(reason: "use first")
+  name: String!
+}
+
+interface Pet {
+  name: String!
+}
+
+type Query {
+  catOrDog(
+    """The name of the pet."""
+    name: String!
+  ): CatOrDog @deprecated(reason: "use pets")
+  """
+  Finds the pets matching a filter, this description is long enough to be split.
+  """
+  pets(filter: Filter, first: Int): [Pet]
+}
+`, printer.PrintSchema(schema))
+}
+
+func TestPrintSchema_Repeatable(t *testing.T) {
+	schema := schemabuilder.MustBuildSDL(`
+directive @tag(name: String!) repeatable on OBJECT
+
+type Query @tag(name: "a") @tag(name: "b") {
+  a: String
+}
+`, schemabuilder.Resolvers{"Query.a": func() *string { return nil }})
+	assert.True(t, schema.Directives["tag"].Repeatable)
+	assert.Contains(t, printer.PrintSchema(schema), "directive @tag(name: String!) repeatable on OBJECT\n")
+
+	introspection.AddIntrospectionToSchema(schema)
+	schemaJSON, err := introspection.ComputeSchemaJSON(schema)
+	assert.NoError(t, err)
+	assert.Contains(t, string(schemaJSON), `"isRepeatable":true`)
+}
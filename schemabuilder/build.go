@@ -131,12 +131,13 @@ func (sb *schemaBuilder) getEnum(typ reflect.Type) *internal.Enum {
 			values = append(values, mapping)
 		}
 		return &internal.Enum{
-			Name:       enum.Name,
-			Values:     values,
-			ValuesDesc: enum.DescMap,
-			ReverseMap: enum.Map,
-			Map:        enum.ReverseMap,
-			Desc:       enum.Desc,
+			Name:             enum.Name,
+			Values:           values,
+			ValuesDesc:       enum.DescMap,
+			DeprecatedValues: enum.DeprecatedMap,
+			ReverseMap:       enum.Map,
+			Map:              enum.ReverseMap,
+			Desc:             enum.Desc,
 		}
 	}
 	return nil
@@ -270,9 +271,12 @@ func (sb *schemaBuilder) getDirective(directive *Directive) (*internal.Directive
 		}
 	}
 
-	arguments, err := sb.getArguments(argType)
-	if err != nil {
-		return nil, err
+	var arguments map[string]*internal.InputField
+	if hasArg {
+		var err error
+		if arguments, err = sb.getArguments(argType); err != nil {
+			return nil, err
+		}
 	}
 
 	return &internal.Directive{
@@ -414,7 +418,8 @@ func (sb *schemaBuilder) buildField(field reflect.StructField) (*internal.Field,
 			}
 			return (*fieldVal).Interface(), nil
 		},
-		Desc: desc,
+		Desc:              desc,
+		DeprecationReason: deprecationReason(field),
 	}, nil
 }
 
@@ -494,18 +499,40 @@ func (sb *schemaBuilder) getField(fnresolve *fieldResolve, src reflect.Type) (*i
 		return nil, err
 	}
 
+	handle := func(ctx context.Context, source, args interface{}) error {
+		for _, handler := range fnresolve.handleChain {
+			if _, err := handler.execute(executeFuncParam{
+				ctx:    ctx,
+				args:   args,
+				source: source,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	afterExecute := func(ctx context.Context, result, args interface{}) (interface{}, error) {
+		var err error
+		for _, execute := range fnresolve.executeChain {
+			if result, err = execute.execute(executeFuncParam{
+				sb:     sb,
+				ctx:    ctx,
+				args:   args,
+				source: result,
+			}); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
 	field := &internal.Field{
 		Type: retType,
 		Args: args,
 		Resolve: func(ctx context.Context, source, args interface{}) (interface{}, error) {
-			for _, handler := range fnresolve.handleChain {
-				if _, err := handler.execute(executeFuncParam{
-					ctx:    ctx,
-					args:   args,
-					source: source,
-				}); err != nil {
-					return nil, err
-				}
+			args = fnresolve.copyArgs(args)
+			if err := handle(ctx, source, args); err != nil {
+				return nil, err
 			}
 			// Set up function arguments.
 			funcInputArgs, err := fctx.prepareResolveArgs(sb, source, fctx.hasArg, args, ctx)
@@ -520,19 +547,44 @@ func (sb *schemaBuilder) getField(fnresolve *fieldResolve, src reflect.Type) (*i
 			if err != nil {
 				return nil, err
 			}
-			for _, execute := range fnresolve.executeChain {
-				if result, err = execute.execute(executeFuncParam{
-					sb:     sb,
-					ctx:    ctx,
-					args:   args,
-					source: result,
-				}); err != nil {
+			return afterExecute(ctx, result, args)
+		},
+		Desc: fnresolve.desc,
+	}
+	if fctx.batch {
+		field.BatchResolve = func(ctx context.Context, sources []interface{}, args interface{}) ([]interface{}, error) {
+			args = fnresolve.copyArgs(args)
+			for _, source := range sources {
+				if err := handle(ctx, source, args); err != nil {
 					return nil, err
 				}
 			}
-			return result, nil
-		},
-		Desc: fnresolve.desc,
+			funcInputArgs, err := fctx.prepareResolveArgs(sb, sources, fctx.hasArg, args, ctx)
+			if err != nil {
+				return nil, err
+			}
+			result, err := fctx.extractResultAndErr(callableFunc.Call(funcInputArgs))
+			if err != nil {
+				return nil, err
+			}
+			results, err := fctx.splitBatchResult(result, fctx.batchSources(funcInputArgs))
+			if err != nil {
+				return nil, err
+			}
+			for i := range results {
+				if results[i], err = afterExecute(ctx, results[i], args); err != nil {
+					return nil, err
+				}
+			}
+			return results, nil
+		}
+		field.Resolve = func(ctx context.Context, source, args interface{}) (interface{}, error) {
+			results, err := field.BatchResolve(ctx, []interface{}{source}, args)
+			if err != nil {
+				return nil, err
+			}
+			return results[0], nil
+		}
 	}
 	for _, build := range fnresolve.buildChain {
 		if _, err := build.execute(buildParam{sb: sb, f: field, functx: fctx, fnresolve: fnresolve}); err != nil {
@@ -612,6 +664,9 @@ type funcContext struct {
 
 	returnsFunc    bool
 	wrapperFuncTyp reflect.Type
+
+	// batch is set when the function takes a slice of sources
+	batch bool
 }
 
 func (f *funcContext) getFuncVal(fn interface{}) (reflect.Value, error) {
@@ -650,6 +705,11 @@ func (funcCtx *funcContext) consumeContextAndSource(in []reflect.Type) []reflect
 			funcCtx.sourceInterface = true
 		}
 		in = in[1:]
+	} else if len(in) > 0 && (in[0] == reflect.SliceOf(funcCtx.typ) || in[0] == reflect.SliceOf(ptr)) {
+		funcCtx.hasSource = true
+		funcCtx.batch = true
+		funcCtx.isPtrFunc = in[0] == reflect.SliceOf(ptr)
+		in = in[1:]
 	}
 
 	return in
@@ -706,6 +766,9 @@ func (funcCtx *funcContext) parseReturnSignature() (err error) {
 // list of "Hats" will resolve the GraphQL type of a "Hat" at this point.
 func (funcCtx *funcContext) getReturnType(sb *schemaBuilder) (internal.Type, error) {
 	var retType internal.Type
+	if funcCtx.batch {
+		return funcCtx.getBatchReturnType(sb)
+	}
 	if funcCtx.returnsFunc {
 		function := funcCtx.funcType.Out(0)
 
@@ -740,6 +803,38 @@ func (funcCtx *funcContext) getReturnType(sb *schemaBuilder) (internal.Type, err
 	return retType, nil
 }
 
+// getBatchReturnType returns the GraphQL type of a single result of a batch function.
+func (funcCtx *funcContext) getBatchReturnType(sb *schemaBuilder) (internal.Type, error) {
+	result, err := funcCtx.batchResultType()
+	if err != nil {
+		return nil, err
+	}
+	return sb.getType(result)
+}
+
+// batchResultType returns the Go type of a single result of a batch function, which returns either
+// a slice with one result per source or a map from source to result.
+func (funcCtx *funcContext) batchResultType() (reflect.Type, error) {
+	if !funcCtx.hasRet || funcCtx.returnsFunc {
+		return nil, fmt.Errorf("%s must return a slice or a map of results", funcCtx.funcType)
+	}
+	out := funcCtx.funcType.Out(0)
+	switch out.Kind() {
+	case reflect.Slice:
+	case reflect.Map:
+		key := funcCtx.typ
+		if funcCtx.isPtrFunc {
+			key = reflect.PtrTo(key)
+		}
+		if out.Key() != key {
+			return nil, fmt.Errorf("%s must return a map keyed by %s", funcCtx.funcType, key)
+		}
+	default:
+		return nil, fmt.Errorf("%s must return a slice or a map of results", funcCtx.funcType)
+	}
+	return out.Elem(), nil
+}
+
 // prepareResolveArgs converts the provided source, args and context into the required list of reflect.Value types that the function needs to be called.
 func (funcCtx *funcContext) prepareResolveArgs(sb *schemaBuilder, source interface{}, hasArgs bool, args interface{}, ctx context.Context) ([]reflect.Value, error) {
 	in := make([]reflect.Value, 0, funcCtx.funcType.NumIn())
@@ -749,23 +844,7 @@ func (funcCtx *funcContext) prepareResolveArgs(sb *schemaBuilder, source interfa
 
 	// Set up source.
 	if funcCtx.hasSource {
-		sourceValue := reflect.ValueOf(source)
-		sourceTyp := sourceValue.Type()
-		ptrSource := sourceValue.Kind() == reflect.Ptr
-		switch {
-		case funcCtx.sourceInterface &&
-			((ptrSource && (sourceTyp.Implements(funcCtx.typ) || sourceTyp.Elem().Implements(funcCtx.typ))) ||
-				(!ptrSource && (sourceTyp.Implements(funcCtx.typ) || reflect.PtrTo(sourceTyp).Implements(funcCtx.typ)))):
-			in = append(in, sourceValue.Convert(funcCtx.typ))
-		case ptrSource && !funcCtx.isPtrFunc:
-			in = append(in, sourceValue.Elem())
-		case !ptrSource && funcCtx.isPtrFunc:
-			copyPtr := reflect.New(funcCtx.typ)
-			copyPtr.Elem().Set(sourceValue)
-			in = append(in, copyPtr)
-		default:
-			in = append(in, sourceValue)
-		}
+		in = append(in, funcCtx.sourceValue(source))
 	}
 
 	// Set up other arguments.
@@ -797,6 +876,75 @@ func (funcCtx *funcContext) prepareResolveArgs(sb *schemaBuilder, source interfa
 	return in, nil
 }
 
+// sourceValue converts source into the type of the source parameter of the function.
+func (funcCtx *funcContext) sourceValue(source interface{}) reflect.Value {
+	if funcCtx.batch {
+		sources := source.([]interface{})
+		elemTyp := funcCtx.typ
+		if funcCtx.isPtrFunc {
+			elemTyp = reflect.PtrTo(elemTyp)
+		}
+		slice := reflect.MakeSlice(reflect.SliceOf(elemTyp), len(sources), len(sources))
+		elem := *funcCtx
+		elem.batch = false
+		for i, s := range sources {
+			slice.Index(i).Set(elem.sourceValue(s))
+		}
+		return slice
+	}
+	sourceValue := reflect.ValueOf(source)
+	sourceTyp := sourceValue.Type()
+	ptrSource := sourceValue.Kind() == reflect.Ptr
+	switch {
+	case funcCtx.sourceInterface &&
+		((ptrSource && (sourceTyp.Implements(funcCtx.typ) || sourceTyp.Elem().Implements(funcCtx.typ))) ||
+			(!ptrSource && (sourceTyp.Implements(funcCtx.typ) || reflect.PtrTo(sourceTyp).Implements(funcCtx.typ)))):
+		return sourceValue.Convert(funcCtx.typ)
+	case ptrSource && !funcCtx.isPtrFunc:
+		return sourceValue.Elem()
+	case !ptrSource && funcCtx.isPtrFunc:
+		copyPtr := reflect.New(funcCtx.typ)
+		copyPtr.Elem().Set(sourceValue)
+		return copyPtr
+	default:
+		return sourceValue
+	}
+}
+
+// batchSources returns the slice of sources among in, the arguments of a call of a batch function.
+func (funcCtx *funcContext) batchSources(in []reflect.Value) reflect.Value {
+	if funcCtx.hasContext {
+		return in[1]
+	}
+	return in[0]
+}
+
+// splitBatchResult splits the result of a batch function into one result per source. The results
+// of a map are looked up with the sources the function was called with, which are the keys of the
+// map even when they were turned into pointers for the function.
+func (funcCtx *funcContext) splitBatchResult(result interface{}, sources reflect.Value) ([]interface{}, error) {
+	value := reflect.ValueOf(result)
+	results := make([]interface{}, sources.Len())
+	if value.Kind() == reflect.Slice {
+		if value.Len() != sources.Len() {
+			return nil, fmt.Errorf("%s returned %d results for %d sources", funcCtx.funcType, value.Len(), sources.Len())
+		}
+		for i := range results {
+			results[i] = value.Index(i).Interface()
+		}
+		return results, nil
+	}
+	for i := range results {
+		// missing sources get the zero value, like any other map lookup
+		v := value.MapIndex(sources.Index(i))
+		if !v.IsValid() {
+			v = reflect.Zero(value.Type().Elem())
+		}
+		results[i] = v.Interface()
+	}
+	return results, nil
+}
+
 // extractResultAndErr converts the response from calling the function into the expected type for the response object (as opposed to a reflect.Value).
 // It also handles reading whether the function ended with errors.
 func (funcCtx *funcContext) extractResultAndErr(out []reflect.Value) (interface{}, error) {
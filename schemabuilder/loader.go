@@ -0,0 +1,160 @@
+package schemabuilder
+
+import (
+	"context"
+	"fmt"
+	"github.com/shyptr/graphql/errors"
+	"sync"
+)
+
+// BatchFunc fetches the values of many keys at once, it returns one value per key, in the same order.
+type BatchFunc func(ctx context.Context, keys []interface{}) ([]interface{}, error)
+
+// Loader caches the values fetched by a BatchFunc, so that every key is fetched at most once.
+// Loaders obtained by GetLoader live as long as the request they were created for.
+type Loader struct {
+	fetch BatchFunc
+	mu    sync.Mutex
+	cache map[interface{}]*loaderEntry
+}
+
+type loaderEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewLoader returns a Loader which fetches missing keys with fetch.
+func NewLoader(fetch BatchFunc) *Loader {
+	return &Loader{fetch: fetch, cache: make(map[interface{}]*loaderEntry)}
+}
+
+// Load returns the value of key, fetching it if it isn't cached yet.
+func (l *Loader) Load(ctx context.Context, key interface{}) (interface{}, error) {
+	values, err := l.LoadMany(ctx, []interface{}{key})
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// LoadMany returns the values of keys, fetching the keys which aren't cached yet with a single call.
+// Keys being fetched by a concurrent call are waited for instead of being fetched again.
+func (l *Loader) LoadMany(ctx context.Context, keys []interface{}) ([]interface{}, error) {
+	entries := make([]*loaderEntry, len(keys))
+	var missing []interface{}
+	var fetching []*loaderEntry
+
+	l.mu.Lock()
+	for i, key := range keys {
+		entry, ok := l.cache[key]
+		if !ok {
+			entry = &loaderEntry{done: make(chan struct{})}
+			l.cache[key] = entry
+			missing = append(missing, key)
+			fetching = append(fetching, entry)
+		}
+		entries[i] = entry
+	}
+	l.mu.Unlock()
+
+	if len(missing) > 0 {
+		values, err := l.safeFetch(ctx, missing)
+		if err == nil && len(values) != len(missing) {
+			err = fmt.Errorf("loader returned %d values for %d keys", len(values), len(missing))
+		}
+		l.mu.Lock()
+		for i, entry := range fetching {
+			if err != nil {
+				entry.err = err
+				// failed keys are not cached, the next load tries again
+				delete(l.cache, missing[i])
+			} else {
+				entry.value = values[i]
+			}
+			close(entry.done)
+		}
+		l.mu.Unlock()
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, entry := range entries {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		values[i] = entry.value
+	}
+	return values, nil
+}
+
+func (l *Loader) safeFetch(ctx context.Context, keys []interface{}) (values []interface{}, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			values, err = nil, errors.NewPanicError(panicErr, errors.Stack())
+		}
+	}()
+	return l.fetch(ctx, keys)
+}
+
+// Prime puts value into the cache for key, unless key is already cached.
+func (l *Loader) Prime(key, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	entry := &loaderEntry{done: make(chan struct{}), value: value}
+	close(entry.done)
+	l.cache[key] = entry
+}
+
+// Clear removes key from the cache.
+func (l *Loader) Clear(key interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, key)
+}
+
+type loadersKey struct{}
+
+// loaders holds the loaders of one request by name.
+type loaders struct {
+	mu      sync.Mutex
+	loaders map[string]*Loader
+}
+
+// WithLoaders makes ctx carry a set of loaders for GetLoader, unless it already does.
+// A ctx which can store values itself, like graphql.Context, is returned as is.
+func WithLoaders(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return ctx
+	}
+	l := &loaders{loaders: make(map[string]*Loader)}
+	if setter, ok := ctx.(interface{ Set(key, value interface{}) }); ok {
+		setter.Set(loadersKey{}, l)
+		return ctx
+	}
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// GetLoader returns the loader named name of the request ctx belongs to, creating it with fetch on first use.
+// The executor sets up the loaders of every request, outside of a request a new Loader is returned each time.
+func GetLoader(ctx context.Context, name string, fetch BatchFunc) *Loader {
+	l, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		return NewLoader(fetch)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	loader, ok := l.loaders[name]
+	if !ok {
+		loader = NewLoader(fetch)
+		l.loaders[name] = loader
+	}
+	return loader
+}
@@ -39,6 +39,19 @@ func parseFieldTag(field reflect.StructField) (skip, null, nonnull bool, name, d
 	return
 }
 
+// deprecationReason returns the reason given by the deprecated tag of field, or the default one
+// when the tag is empty, and nil when the field has no deprecated tag.
+func deprecationReason(field reflect.StructField) *string {
+	reason, ok := field.Tag.Lookup("deprecated")
+	if !ok {
+		return nil
+	}
+	if reason == "" {
+		reason = DefaultDeprecationReason
+	}
+	return &reason
+}
+
 func getField(source interface{}, name string) reflect.Type {
 	typ := reflect.TypeOf(source)
 	if field, ok := typ.FieldByName(name); ok {
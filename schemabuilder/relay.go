@@ -88,6 +88,9 @@ func RelayKey(typ interface{}, key string) {
 
 var RelayConnection afterBuildFunc = func(param buildParam) error {
 	sb, field, fctx, fnresolve := param.sb, param.f, param.functx, param.fnresolve
+	if fctx.batch {
+		return fmt.Errorf("relay connection can not be used with a batch function")
+	}
 	var argAnonymous, retAnonymous bool
 	if fctx.hasArg {
 		if cf, ok := fctx.argTyp.FieldByName(connectionArgsType.Elem().Name()); ok && cf.Type == connectionArgsType && cf.Anonymous {
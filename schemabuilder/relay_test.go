@@ -36,3 +36,57 @@ func TestRelayConnection(t *testing.T) {
 		fmt.Println(result)
 	})
 }
+
+// the arguments of a selection are shared by the items of a list, which resolve concurrently
+func TestRelayConnectionInList(t *testing.T) {
+	type Page struct {
+		*schemabuilder.PaginationInfo
+		Slice []Slice
+	}
+	type Filter struct {
+		Prefix string `graphql:"prefix"`
+	}
+	type Shelf struct {
+		Name string `graphql:"name"`
+	}
+	builder := schemabuilder.NewSchema()
+	builder.Object("Slice", Slice{})
+	schemabuilder.RelayKey(Slice{}, "id")
+	builder.Object("Page", Page{})
+	builder.InputObject("Filter", Filter{}).FieldDefault("prefix", "shelf")
+	shelf := builder.Object("Shelf", Shelf{})
+	shelf.FieldFunc("items", func(args struct {
+		*schemabuilder.ConnectionArgs
+	}) *Page {
+		return &Page{
+			PaginationInfo: &schemabuilder.PaginationInfo{TotalCount: len(slice)},
+			Slice:          slice[:*args.First],
+		}
+	}, "", schemabuilder.RelayConnection)
+	shelf.FieldFunc("label", func(source Shelf, args struct {
+		Filter *Filter `graphql:"filter"`
+	}) string {
+		return args.Filter.Prefix + source.Name
+	}, "")
+	builder.Query().FieldFunc("shelves", func() []Shelf {
+		shelves := make([]Shelf, 20)
+		for i := range shelves {
+			shelves[i].Name = fmt.Sprint(i)
+		}
+		return shelves
+	}, "")
+	schema := builder.MustBuild()
+
+	result, err := execution.Do(schema, execution.Params{Query: `{ shelves { label(filter: {}) items(first: 1) { totalCount edges { node { s } } } } }`})
+	assert.Equal(t, errors.MultiError(nil), err)
+	shelves, _ := result.(*execution.OrderedMap).Get("shelves")
+	if assert.Len(t, shelves, 20) {
+		for i, shelf := range shelves.([]interface{}) {
+			label, _ := shelf.(*execution.OrderedMap).Get("label")
+			assert.Equal(t, fmt.Sprint("shelf", i), label)
+			items, _ := shelf.(*execution.OrderedMap).Get("items")
+			totalCount, _ := items.(*execution.OrderedMap).Get("totalCount")
+			assert.Equal(t, 5, totalCount)
+		}
+	}
+}
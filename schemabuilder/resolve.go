@@ -39,11 +39,16 @@ func (sb *schemaBuilder) getArguments(typ reflect.Type) (map[string]*internal.In
 				defaultValue = f.DefaultValue
 			}
 		}
+		reason := deprecationReason(field)
+		if _, ok := fieldTyp.(*internal.NonNull); ok && reason != nil && defaultValue == nil {
+			return nil, fmt.Errorf("%s %s is required and can not be deprecated", typ.String(), name)
+		}
 		args[name] = &internal.InputField{
-			Name:         name,
-			Type:         fieldTyp,
-			Desc:         desc,
-			DefaultValue: defaultValue,
+			Name:              name,
+			Type:              fieldTyp,
+			Desc:              desc,
+			DefaultValue:      defaultValue,
+			DeprecationReason: reason,
 		}
 	}
 	sb.cacheTypes[typ] = sb.converToStruct(typ)
@@ -133,14 +138,11 @@ func (sb *schemaBuilder) getArgResolve(src reflect.Type, typ internal.Type) erro
 
 func (sb *schemaBuilder) converToStruct(typ reflect.Type) resolveFunc {
 	return func(value interface{}) (interface{}, error) {
+		// args may be shared by concurrent resolutions, the defaults are not written into it
 		args := value.(map[string]interface{})
-
+		var defaults map[string]*inputFieldResolve
 		if input, ok := sb.inputObjects[typ]; ok {
-			for name, f := range input.Fields {
-				if _, ok := args[name]; !ok {
-					args[name] = f.DefaultValue
-				}
-			}
+			defaults = input.Fields
 		}
 
 		conver := make(map[string]interface{})
@@ -154,7 +156,11 @@ func (sb *schemaBuilder) converToStruct(typ reflect.Type) resolveFunc {
 			for ftyp.Kind() == reflect.Ptr {
 				ftyp = ftyp.Elem()
 			}
-			if v, ok := args[name]; ok {
+			v, ok := args[name]
+			if f, isInput := defaults[name]; !ok && isInput {
+				v, ok = f.DefaultValue, true
+			}
+			if ok {
 				vv, err := sb.cacheTypes[ftyp](v)
 				if err != nil {
 					return nil, err
@@ -42,6 +42,8 @@ func NewSchema() *Schema {
 		directives: map[string]*Directive{
 			"include": IncludeDirective,
 			"skip":    SkipDirective,
+			"defer":   DeferDirective,
+			"stream":  StreamDirective,
 		},
 	}
 
@@ -57,6 +59,18 @@ type DescField struct {
 	Desc  string
 }
 
+// only use in enum definition
+// can set description and deprecation reason for enum value
+// it is apart from DescField, whose unkeyed DescField{value, desc} literals would no longer
+// compile with one more field
+var DeprecatedFieldTyp = reflect.TypeOf(DeprecatedField{})
+
+type DeprecatedField struct {
+	Field  interface{}
+	Desc   string
+	Reason string
+}
+
 // Enum registers an enumType in the schema. The val should be any arbitrary value
 // of the enumType to be used for reflection, and the enumMap should be
 // the corresponding map of the enums.
@@ -73,7 +87,7 @@ type DescField struct {
 //   s.Enum("number",enumType(1), map[string]interface{}{
 //     "one":   DescField{one,"the first one"},
 //     "two":   two,
-//     "three": three,
+//     "three": DeprecatedField{three,"","counts stop at two"},
 //   },"")
 func (s *Schema) Enum(name string, val interface{}, enum interface{}, desc ...string) {
 	if name == "" {
@@ -93,6 +107,7 @@ func (s *Schema) Enum(name string, val interface{}, enum interface{}, desc ...st
 	rMap := make(map[interface{}]string)
 	eMap := make(map[string]interface{})
 	dMap := make(map[string]string)
+	deprecated := make(map[string]string)
 	for em := enumMap.MapRange(); em.Next(); {
 		desc := ""
 		val := em.Value()
@@ -101,9 +116,12 @@ func (s *Schema) Enum(name string, val interface{}, enum interface{}, desc ...st
 		}
 		valInterface := val.Interface()
 		if val.Kind() != typ.Kind() {
-			if val.Type() == DescFieldTyp {
+			if val.Type() == DescFieldTyp || val.Type() == DeprecatedFieldTyp {
 				value := reflect.ValueOf(valInterface)
 				desc = value.FieldByName("Desc").String()
+				if val.Type() == DeprecatedFieldTyp {
+					deprecated[em.Key().String()] = value.FieldByName("Reason").String()
+				}
 				valInterface = value.FieldByName("Field").Interface()
 				if reflect.TypeOf(valInterface).Kind() != typ.Kind() {
 					panic("enum descField's field types are not equal")
@@ -122,12 +140,13 @@ func (s *Schema) Enum(name string, val interface{}, enum interface{}, desc ...st
 		d = desc[0]
 	}
 	s.enums[name] = &Enum{
-		Name:       name,
-		Desc:       d,
-		Type:       val,
-		Map:        eMap,
-		ReverseMap: rMap,
-		DescMap:    dMap,
+		Name:          name,
+		Desc:          d,
+		Type:          val,
+		Map:           eMap,
+		ReverseMap:    rMap,
+		DescMap:       dMap,
+		DeprecatedMap: deprecated,
 	}
 }
 
@@ -297,7 +316,11 @@ func (s *Schema) Scalar(name string, tp interface{}, options ...interface{}) *Sc
 		Serialize:  Serialize,
 		ParseValue: parseValue,
 		ParseLiteral: func(value ast.Value) error {
-			_, err := parseValue(value.GetValue())
+			v, jsonErr := internal.ValueToJson(value, nil)
+			if jsonErr != nil {
+				return jsonErr
+			}
+			_, err := parseValue(v)
 			return err
 		},
 	}
@@ -0,0 +1,1239 @@
+package schemabuilder
+
+import (
+	"context"
+	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Resolvers implement a schema written in the schema definition language, see BuildSDL. The keys are
+//
+//	"Type.field"  the function resolving a field of an object, it takes [context][, source][, args]
+//	              like the functions of FieldFunc, args being there when the field has arguments
+//	"Type"        a value of the Go type of an object or an input object, the values of an enum
+//	              as a map[string]interface{}, or the *Scalar implementing a scalar
+//	"@directive"  the function of a directive, like the functions of Schema.Directive
+type Resolvers map[string]interface{}
+
+// sdlScalars are the specified scalars of schemas built from SDL, where they differ from those of code-first schemas.
+var sdlScalars = map[string]*Scalar{
+	"Float": Float64,
+	"ID": {
+		Name: "ID",
+		Desc: ID.Desc,
+		Type: "",
+		Serialize: func(value interface{}) (interface{}, error) {
+			switch value := value.(type) {
+			case Id:
+				return value.Value, nil
+			case *Id:
+				return value.Value, nil
+			}
+			// the Go types scalarHolds accepts for IDs, named ones included
+			switch v := reflect.ValueOf(value); v.Kind() {
+			case reflect.String:
+				return v.String(), nil
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return strconv.FormatInt(v.Int(), 10), nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return strconv.FormatUint(v.Uint(), 10), nil
+			}
+			return nil, fmt.Errorf("unexpected type %T for ID", value)
+		},
+		ParseValue: func(value interface{}) (interface{}, error) {
+			switch value := value.(type) {
+			case string:
+				return value, nil
+			case float64:
+				return strconv.FormatInt(int64(value), 10), nil
+			}
+			return nil, fmt.Errorf("not a ID")
+		},
+	},
+}
+
+var specifiedScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+var specifiedDirectives = map[string]bool{
+	"include": true, "skip": true, "deprecated": true, "specifiedBy": true, "defer": true, "stream": true,
+}
+
+// executableLocations are the locations of directives which need a function to be executed.
+var executableLocations = map[string]bool{
+	"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true, "FRAGMENT_DEFINITION": true,
+	"FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true, "VARIABLE_DEFINITION": true,
+}
+
+// sdlBuilder builds a schema from its definitions, binding the Go types of the resolvers to its types.
+type sdlBuilder struct {
+	sb          *schemaBuilder
+	resolvers   Resolvers
+	definitions map[string]ast.TypeDefinition
+	directives  map[string]*ast.DirectiveDefinition
+	roots       map[ast.OperationType]string
+	types       map[string]internal.NamedType
+	// scalars are the implementations of the scalars, by name
+	scalars map[string]*Scalar
+	// goTypes are the Go types the objects are bound to, by name
+	goTypes map[string]reflect.Type
+	// enumValues are the Go types of the enum values given in the resolvers, by enum
+	enumValues map[string]reflect.Type
+	// enumTypes are the enums of the named Go types holding enum values
+	enumTypes map[reflect.Type]string
+	// inputs are the input objects, or the fields with arguments, of the structs holding input values
+	inputs map[reflect.Type]string
+}
+
+// BuildSDL builds the schema defined by source in the schema definition language, implemented by resolvers.
+//
+// The functions resolving fields are bound like those of code-first fields: their arguments are given in a
+// struct whose fields are named by their graphql tag, and the objects they take and return are bound to
+// their Go types, whose fields resolve the fields which have no function. The Go types are checked against
+// the types of the schema, any mismatch fails the build. Enums take the names of their values as values,
+// unless they are given in resolvers, and the scalars of code-first schemas, like Time, need no implementation.
+func BuildSDL(source string, resolvers Resolvers) (*internal.Schema, error) {
+	doc, err := internal.ParseDocument(source)
+	if err != nil {
+		return nil, err
+	}
+	b := &sdlBuilder{
+		sb: &schemaBuilder{
+			types:        make(map[reflect.Type]internal.Type),
+			cacheTypes:   make(map[reflect.Type]resolveFunc),
+			objects:      make(map[reflect.Type]*Object),
+			enums:        make(map[reflect.Type]*Enum),
+			inputObjects: make(map[reflect.Type]*InputObject),
+			interfaces:   make(map[reflect.Type]*Interface),
+			scalars:      make(map[reflect.Type]*Scalar),
+			unions:       make(map[reflect.Type]*Union),
+		},
+		resolvers:   resolvers,
+		definitions: make(map[string]ast.TypeDefinition),
+		directives:  make(map[string]*ast.DirectiveDefinition),
+		roots:       make(map[ast.OperationType]string),
+		types:       make(map[string]internal.NamedType),
+		scalars:     make(map[string]*Scalar),
+		goTypes:     make(map[string]reflect.Type),
+		enumValues:  make(map[string]reflect.Type),
+		enumTypes:   make(map[reflect.Type]string),
+		inputs:      make(map[reflect.Type]string),
+	}
+	// the arguments of directive functions are built from their Go types
+	for _, scalar := range scalars {
+		if scalar.Type != nil {
+			b.sb.scalars[reflect.TypeOf(scalar.Type)] = scalar
+		}
+	}
+	if err := b.collect(doc); err != nil {
+		return nil, err
+	}
+	return b.build()
+}
+
+// MustBuildSDL builds a schema from SDL and panics if an error occurs.
+func MustBuildSDL(source string, resolvers Resolvers) *internal.Schema {
+	built, err := BuildSDL(source, resolvers)
+	if err != nil {
+		panic(err)
+	}
+	return built
+}
+
+// collect gathers the definitions of doc by name, with their extensions merged in.
+func (b *sdlBuilder) collect(doc *ast.Document) error {
+	var extensions []ast.TypeSystemExtension
+	var schemaDefined bool
+	for _, definition := range doc.Definition {
+		switch definition := definition.(type) {
+		case *ast.SchemaDefinition:
+			if schemaDefined {
+				return fmt.Errorf("schema is defined more than once")
+			}
+			schemaDefined = true
+			if err := b.addRoots(definition.OperationTypes); err != nil {
+				return err
+			}
+		case *ast.DirectiveDefinition:
+			name := definition.Name.Name
+			if _, ok := b.directives[name]; ok {
+				return fmt.Errorf("directive @%s is defined more than once", name)
+			}
+			b.directives[name] = definition
+		case ast.TypeDefinition:
+			name := typeDefinitionName(definition)
+			if _, ok := b.definitions[name]; ok {
+				return fmt.Errorf("type %s is defined more than once", name)
+			}
+			b.definitions[name] = definition
+		case ast.TypeSystemExtension:
+			extensions = append(extensions, definition)
+		default:
+			return fmt.Errorf("a schema can not be built from %s definitions", definition.GetKind())
+		}
+	}
+	for _, extension := range extensions {
+		if err := b.extend(extension); err != nil {
+			return err
+		}
+	}
+	if !schemaDefined {
+		for _, operation := range []ast.OperationType{ast.Query, ast.Mutation, ast.Subscription} {
+			name := strings.Title(strings.ToLower(string(operation)))
+			if _, ok := b.definitions[name]; ok && b.roots[operation] == "" {
+				b.roots[operation] = name
+			}
+		}
+	}
+	return nil
+}
+
+func (b *sdlBuilder) addRoots(operations []*ast.OperationTypeDefinition) error {
+	for _, operation := range operations {
+		if _, ok := b.roots[operation.Operation]; ok {
+			return fmt.Errorf("the %s type is defined more than once", strings.ToLower(string(operation.Operation)))
+		}
+		b.roots[operation.Operation] = operation.Type.Name.Name
+	}
+	return nil
+}
+
+func (b *sdlBuilder) extend(extension ast.TypeSystemExtension) error {
+	var name string
+	var extended bool
+	switch extension := extension.(type) {
+	case *ast.SchemaExtension:
+		return b.addRoots(extension.RootOperation)
+	case *ast.ScalarExtension:
+		name = extension.Name.Name
+		_, extended = b.definitions[name].(*ast.ScalarDefinition)
+	case *ast.ObjectExtension:
+		name = extension.Name.Name
+		if object, ok := b.definitions[name].(*ast.ObjectDefinition); ok {
+			object.Interfaces = append(object.Interfaces, extension.Interfaces...)
+			object.Directives = append(object.Directives, extension.Directives...)
+			object.Fields = append(object.Fields, extension.Fields...)
+			extended = true
+		}
+	case *ast.InterfaceExtension:
+		name = extension.Name.Name
+		if iface, ok := b.definitions[name].(*ast.InterfaceDefinition); ok {
+			iface.Interfaces = append(iface.Interfaces, extension.Interfaces...)
+			iface.Directives = append(iface.Directives, extension.Directives...)
+			iface.Fields = append(iface.Fields, extension.Fields...)
+			extended = true
+		}
+	case *ast.UnionExtension:
+		name = extension.Name.Name
+		if union, ok := b.definitions[name].(*ast.UnionDefinition); ok {
+			union.Directives = append(union.Directives, extension.Directives...)
+			union.Members = append(union.Members, extension.Members...)
+			extended = true
+		}
+	case *ast.EnumExtension:
+		name = extension.Name.Name
+		if enum, ok := b.definitions[name].(*ast.EnumDefinition); ok {
+			enum.Directives = append(enum.Directives, extension.Directives...)
+			enum.Values = append(enum.Values, extension.Values...)
+			extended = true
+		}
+	case *ast.InputObjectExtension:
+		name = extension.Name.Name
+		if input, ok := b.definitions[name].(*ast.InputObjectDefinition); ok {
+			input.Directives = append(input.Directives, extension.Directives...)
+			input.InputFields = append(input.InputFields, extension.InputFields...)
+			extended = true
+		}
+	}
+	if !extended {
+		return fmt.Errorf("can not extend %s, it is not defined as a type of the same kind", name)
+	}
+	return nil
+}
+
+func (b *sdlBuilder) build() (*internal.Schema, error) {
+	// the types are created before their fields, which can refer to any of them
+	names := sortedKeys(b.definitions)
+	for _, name := range names {
+		if err := b.createType(b.definitions[name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range names {
+		if err := b.fillType(b.definitions[name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range names {
+		if object, ok := b.types[name].(*internal.Object); ok {
+			for _, iface := range sortedKeys(object.Interfaces) {
+				for _, field := range sortedKeys(object.Interfaces[iface].Fields) {
+					if _, ok := object.Fields[field]; !ok {
+						return nil, fmt.Errorf("%s must have the field %s of its interface %s", name, field, iface)
+					}
+				}
+			}
+		}
+	}
+	// the conversions of the arguments are cached by Go type, the specified scalars keep those of their Go types
+	// whichever enum or scalar is bound to them first
+	for name, goType := range map[string]reflect.Type{
+		"ID": reflect.TypeOf(""), "Int": reflect.TypeOf(0), "Float": reflect.TypeOf(float64(0)), "Boolean": reflect.TypeOf(true),
+	} {
+		scalar, err := b.namedType(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.sb.getArgResolve(goType, scalar); err != nil {
+			return nil, err
+		}
+	}
+
+	schema := &internal.Schema{TypeMap: b.types}
+	for _, root := range []struct {
+		operation ast.OperationType
+		goType    interface{}
+		typ       *internal.Type
+	}{
+		{ast.Query, Query{}, &schema.Query},
+		{ast.Mutation, Mutation{}, &schema.Mutation},
+		{ast.Subscription, Subscription{}, &schema.Subscription},
+	} {
+		name, ok := b.roots[root.operation]
+		if !ok {
+			continue
+		}
+		object, ok := b.types[name].(*internal.Object)
+		if !ok {
+			return nil, fmt.Errorf("the %s type %s must be an object", strings.ToLower(string(root.operation)), name)
+		}
+		if err := b.bindObject(object, reflect.TypeOf(root.goType)); err != nil {
+			return nil, err
+		}
+		*root.typ = object
+	}
+	if schema.Query == nil {
+		return nil, fmt.Errorf("schema must have a query type")
+	}
+
+	if err := b.bindResolvers(); err != nil {
+		return nil, err
+	}
+	if err := b.bindStructFields(); err != nil {
+		return nil, err
+	}
+	if err := b.resolveInterfaces(); err != nil {
+		return nil, err
+	}
+	directives, err := b.buildDirectives()
+	if err != nil {
+		return nil, err
+	}
+	schema.Directives = directives
+	return schema, nil
+}
+
+func (b *sdlBuilder) createType(definition ast.TypeDefinition) error {
+	switch definition := definition.(type) {
+	case *ast.ScalarDefinition:
+		scalar, err := b.newScalar(definition.Name.Name, definition)
+		if err != nil {
+			return err
+		}
+		b.types[scalar.Name] = scalar
+	case *ast.ObjectDefinition:
+		b.types[definition.Name.Name] = &internal.Object{
+			Name:       definition.Name.Name,
+			Desc:       description(definition.Desc),
+			Interfaces: make(map[string]*internal.Interface),
+			Fields:     make(map[string]*internal.Field),
+		}
+	case *ast.InterfaceDefinition:
+		b.types[definition.Name.Name] = &internal.Interface{
+			Name:          definition.Name.Name,
+			Desc:          description(definition.Desc),
+			Fields:        make(map[string]*internal.Field),
+			Interfaces:    make(map[string]*internal.Interface),
+			PossibleTypes: make(map[string]*internal.Object),
+		}
+	case *ast.UnionDefinition:
+		b.types[definition.Name.Name] = &internal.Union{
+			Name:  definition.Name.Name,
+			Desc:  description(definition.Desc),
+			Types: make(map[string]*internal.Object),
+		}
+	case *ast.EnumDefinition:
+		enum, err := b.newEnum(definition)
+		if err != nil {
+			return err
+		}
+		b.types[enum.Name] = enum
+	case *ast.InputObjectDefinition:
+		b.types[definition.Name.Name] = &internal.InputObject{
+			Name: definition.Name.Name,
+			Desc: description(definition.Desc),
+		}
+	}
+	return nil
+}
+
+// newScalar creates the scalar name, implemented by the *Scalar given in the resolvers or by one of
+// the scalars of code-first schemas. The specified scalars have no definition.
+func (b *sdlBuilder) newScalar(name string, definition *ast.ScalarDefinition) (*internal.Scalar, error) {
+	scalar, ok := sdlScalars[name]
+	if !ok {
+		scalar, ok = scalars[name]
+	}
+	if resolver, given := b.resolvers[name]; given {
+		if scalar, ok = resolver.(*Scalar); !ok {
+			return nil, fmt.Errorf("scalar %s must be implemented by a *Scalar, not %T", name, resolver)
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("scalar %s has no implementation, give its *Scalar as resolvers[%q]", name, name)
+	}
+	b.scalars[name] = scalar
+	desc := scalar.Desc
+	if definition != nil {
+		desc = description(definition.Desc)
+	}
+	serialize := scalar.Serialize
+	return &internal.Scalar{
+		Name: name,
+		Desc: desc,
+		Serialize: func(value interface{}) (interface{}, error) {
+			return serialize(basicValue(value))
+		},
+		ParseValue:   scalar.ParseValue,
+		ParseLiteral: scalar.ParseLiteral,
+	}, nil
+}
+
+// basicValue converts values of named basic types, like the Go types of enums, to their basic type,
+// as the serializers only know the basic types.
+func basicValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.Type().PkgPath() == "" {
+		return value
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return value
+}
+
+// newEnum creates an enum, whose values are their names unless the resolvers give them.
+func (b *sdlBuilder) newEnum(definition *ast.EnumDefinition) (*internal.Enum, error) {
+	name := definition.Name.Name
+	enum := &internal.Enum{
+		Name:             name,
+		Desc:             description(definition.Desc),
+		ValuesDesc:       make(map[string]string),
+		ReverseMap:       make(map[string]interface{}),
+		Map:              make(map[interface{}]string),
+		DeprecatedValues: make(map[string]string),
+	}
+	var values map[string]interface{}
+	if resolver, ok := b.resolvers[name]; ok {
+		if values, ok = resolver.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("the values of enum %s must be given as a map[string]interface{}, not %T", name, resolver)
+		}
+	}
+	for _, definition := range definition.Values {
+		valueName := definition.Value.Value
+		if _, ok := enum.ReverseMap[valueName]; ok {
+			return nil, fmt.Errorf("enum %s has the value %s more than once", name, valueName)
+		}
+		enum.Values = append(enum.Values, valueName)
+		if desc := description(definition.Desc); desc != "" {
+			enum.ValuesDesc[valueName] = desc
+		}
+		if reason := deprecation(definition.Directives); reason != nil {
+			enum.DeprecatedValues[valueName] = *reason
+		}
+		var value interface{} = valueName
+		if values != nil {
+			var ok bool
+			if value, ok = values[valueName]; !ok {
+				return nil, fmt.Errorf("enum %s has no Go value for %s", name, valueName)
+			}
+			typ := reflect.TypeOf(value)
+			if valueType, ok := b.enumValues[name]; ok && valueType != typ {
+				return nil, fmt.Errorf("the values of enum %s must be of one Go type, not %s and %s", name, valueType, typ)
+			}
+			b.enumValues[name] = typ
+		}
+		enum.ReverseMap[valueName] = value
+		enum.Map[value] = valueName
+	}
+	for _, valueName := range sortedKeys(values) {
+		if _, ok := enum.ReverseMap[valueName]; !ok {
+			return nil, fmt.Errorf("enum %s has no value %s", name, valueName)
+		}
+	}
+	// the conversions of arguments are cached by Go type, which the enum must have to itself
+	if typ, ok := b.enumValues[name]; ok {
+		if typ.PkgPath() == "" {
+			return nil, fmt.Errorf("the values of enum %s must be of a named Go type, not %s", name, typ)
+		}
+		if other, ok := b.enumTypes[typ]; ok {
+			return nil, fmt.Errorf("the values of enums %s and %s are both of %s", other, name, typ)
+		}
+		b.enumTypes[typ] = name
+		if err := b.sb.getArgResolve(typ, enum); err != nil {
+			return nil, err
+		}
+	}
+	return enum, nil
+}
+
+func (b *sdlBuilder) fillType(definition ast.TypeDefinition) error {
+	var err error
+	switch definition := definition.(type) {
+	case *ast.ObjectDefinition:
+		object := b.types[definition.Name.Name].(*internal.Object)
+		if err = b.implement(object.Name, object.Interfaces, definition.Interfaces); err != nil {
+			return err
+		}
+		for _, iface := range object.Interfaces {
+			iface.PossibleTypes[object.Name] = object
+		}
+		object.Fields, err = b.fields(object.Name, definition.Fields)
+	case *ast.InterfaceDefinition:
+		iface := b.types[definition.Name.Name].(*internal.Interface)
+		if err = b.implement(iface.Name, iface.Interfaces, definition.Interfaces); err != nil {
+			return err
+		}
+		iface.Fields, err = b.fields(iface.Name, definition.Fields)
+	case *ast.UnionDefinition:
+		union := b.types[definition.Name.Name].(*internal.Union)
+		for _, member := range definition.Members {
+			object, ok := b.types[member.Name.Name].(*internal.Object)
+			if !ok {
+				return fmt.Errorf("the member %s of union %s must be an object", member.Name.Name, union.Name)
+			}
+			union.Types[object.Name] = object
+		}
+	case *ast.InputObjectDefinition:
+		input := b.types[definition.Name.Name].(*internal.InputObject)
+		input.Fields, err = b.inputValues(input.Name, definition.InputFields)
+	}
+	return err
+}
+
+func (b *sdlBuilder) implement(name string, interfaces map[string]*internal.Interface, names []*ast.Named) error {
+	for _, named := range names {
+		iface, ok := b.types[named.Name.Name].(*internal.Interface)
+		if !ok {
+			return fmt.Errorf("%s can only implement interfaces, %s is not one", name, named.Name.Name)
+		}
+		interfaces[iface.Name] = iface
+	}
+	return nil
+}
+
+func (b *sdlBuilder) fields(typeName string, definitions []*ast.FieldDefinition) (map[string]*internal.Field, error) {
+	fields := make(map[string]*internal.Field, len(definitions))
+	for _, definition := range definitions {
+		name := definition.Name.Name
+		if _, ok := fields[name]; ok {
+			return nil, fmt.Errorf("%s.%s is defined more than once", typeName, name)
+		}
+		typ, err := b.typeOf(definition.Type)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := namedType(typ).(*internal.InputObject); ok {
+			return nil, fmt.Errorf("%s.%s can not be of the input object %s", typeName, name, typ)
+		}
+		args, err := b.inputValues(typeName+"."+name, definition.Argument)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = &internal.Field{
+			Name:              name,
+			Type:              typ,
+			Args:              args,
+			Desc:              description(definition.Desc),
+			DeprecationReason: deprecation(definition.Directives),
+		}
+	}
+	return fields, nil
+}
+
+// inputValues creates the arguments of a field or a directive, or the fields of an input object.
+// Their default values are kept in their JSON form, which converToStruct parses like variables.
+func (b *sdlBuilder) inputValues(owner string, definitions []*ast.InputValueDefinition) (map[string]*internal.InputField, error) {
+	values := make(map[string]*internal.InputField, len(definitions))
+	for _, definition := range definitions {
+		name := definition.Name.Name
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("%s has %s more than once", owner, name)
+		}
+		typ, err := b.typeOf(definition.Type)
+		if err != nil {
+			return nil, err
+		}
+		switch namedType(typ).(type) {
+		case *internal.Scalar, *internal.Enum, *internal.InputObject:
+		default:
+			return nil, fmt.Errorf("%s %s must be of an input type, not %s", owner, name, typ)
+		}
+		var defaultValue interface{}
+		if definition.DefaultValue != nil {
+			value, err := internal.ValueToJson(definition.DefaultValue, nil)
+			if err != nil {
+				return nil, err
+			}
+			defaultValue = value
+		}
+		values[name] = &internal.InputField{
+			Name:              name,
+			Type:              typ,
+			Desc:              description(definition.Desc),
+			DefaultValue:      defaultValue,
+			DeprecationReason: deprecation(definition.Directives),
+		}
+	}
+	return values, nil
+}
+
+func (b *sdlBuilder) typeOf(typ ast.Type) (internal.Type, error) {
+	switch typ := typ.(type) {
+	case *ast.NonNull:
+		inner, err := b.typeOf(typ.Type)
+		if err != nil {
+			return nil, err
+		}
+		return &internal.NonNull{Type: inner}, nil
+	case *ast.List:
+		inner, err := b.typeOf(typ.Type)
+		if err != nil {
+			return nil, err
+		}
+		return &internal.List{Type: inner}, nil
+	case *ast.Named:
+		return b.namedType(typ.Name.Name)
+	}
+	return nil, fmt.Errorf("unknown type %s", typ)
+}
+
+// namedType returns the type name, the specified scalars are created when first used.
+func (b *sdlBuilder) namedType(name string) (internal.NamedType, error) {
+	if typ, ok := b.types[name]; ok {
+		return typ, nil
+	}
+	if !specifiedScalars[name] {
+		return nil, fmt.Errorf("unknown type %s", name)
+	}
+	scalar, err := b.newScalar(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	b.types[name] = scalar
+	return scalar, nil
+}
+
+// bindResolvers binds the resolvers to the types they implement, in the order of their keys.
+func (b *sdlBuilder) bindResolvers() error {
+	for _, key := range sortedKeys(b.resolvers) {
+		resolver := b.resolvers[key]
+		if resolver == nil {
+			return fmt.Errorf("resolver %s is nil", key)
+		}
+		if strings.HasPrefix(key, "@") {
+			if _, ok := b.directives[key[1:]]; !ok {
+				return fmt.Errorf("resolver %s is not for a directive of the schema", key)
+			}
+			continue
+		}
+		if i := strings.Index(key, "."); i >= 0 {
+			object, ok := b.types[key[:i]].(*internal.Object)
+			if !ok {
+				return fmt.Errorf("resolver %s is not for a field of an object", key)
+			}
+			field, ok := object.Fields[key[i+1:]]
+			if !ok {
+				return fmt.Errorf("resolver %s is not for a field of an object", key)
+			}
+			if err := b.bindField(object, field, resolver); err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+			continue
+		}
+		var err error
+		switch typ := b.types[key].(type) {
+		case *internal.Object:
+			err = b.bindObject(typ, indirect(reflect.TypeOf(resolver)))
+		case *internal.InputObject:
+			err = b.bindInput(reflect.TypeOf(resolver), typ)
+		case *internal.Enum, *internal.Scalar:
+			// bound when the type was created
+		default:
+			err = fmt.Errorf("resolver %s is not for a type of the schema", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindField resolves field with the function fn. Its parameters and results are read by a funcContext
+// like the functions of code-first fields, and their Go types are checked against the types of field.
+func (b *sdlBuilder) bindField(object *internal.Object, field *internal.Field, fn interface{}) error {
+	fctx := &funcContext{}
+	callableFunc, err := fctx.getFuncVal(fn)
+	if err != nil {
+		return err
+	}
+	in := fctx.getFuncInputTypes()
+
+	params := in
+	if len(params) > 0 && params[0] == contextType {
+		params = params[1:]
+	}
+	var args int
+	if len(field.Args) > 0 {
+		args = 1
+	}
+	switch len(params) - args {
+	case 0:
+		if len(in) > 0 && in[0] == contextType {
+			fctx.hasContext = true
+			in = in[1:]
+		}
+	case 1:
+		// a slice of sources makes a batch function
+		source := params[0]
+		if source.Kind() == reflect.Slice {
+			source = source.Elem()
+		}
+		source = indirect(source)
+		if err := b.bindObject(object, source); err != nil {
+			return err
+		}
+		fctx.typ = source
+		in = fctx.consumeContextAndSource(in)
+	default:
+		return fmt.Errorf("%s arguments should be [context][, source][, args]", fctx.funcType)
+	}
+	if args > 0 {
+		fctx.argTyp = in[0]
+		fctx.hasArg = true
+		if err := b.bindStruct(fctx.argTyp, field.Args, object.Name+"."+field.Name); err != nil {
+			return err
+		}
+	}
+
+	if err := fctx.parseReturnSignature(); err != nil {
+		return err
+	}
+	var ret reflect.Type
+	switch {
+	case fctx.batch:
+		if ret, err = fctx.batchResultType(); err != nil {
+			return err
+		}
+	case fctx.returnsFunc:
+		return fmt.Errorf("%s can not return a function in a schema built from SDL", fctx.funcType)
+	case fctx.hasRet:
+		ret = fctx.funcType.Out(0)
+	default:
+		// like code-first fields, functions without result resolve to true
+		ret = reflect.TypeOf(true)
+	}
+	if err := b.bindOutput(ret, field.Type); err != nil {
+		return err
+	}
+
+	field.Resolve = func(ctx context.Context, source, args interface{}) (interface{}, error) {
+		funcInputArgs, err := fctx.prepareResolveArgs(b.sb, source, fctx.hasArg, args, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return fctx.extractResultAndErr(callableFunc.Call(funcInputArgs))
+	}
+	if fctx.batch {
+		field.BatchResolve = func(ctx context.Context, sources []interface{}, args interface{}) ([]interface{}, error) {
+			funcInputArgs, err := fctx.prepareResolveArgs(b.sb, sources, fctx.hasArg, args, ctx)
+			if err != nil {
+				return nil, err
+			}
+			result, err := fctx.extractResultAndErr(callableFunc.Call(funcInputArgs))
+			if err != nil {
+				return nil, err
+			}
+			return fctx.splitBatchResult(result, fctx.batchSources(funcInputArgs))
+		}
+		field.Resolve = func(ctx context.Context, source, args interface{}) (interface{}, error) {
+			results, err := field.BatchResolve(ctx, []interface{}{source}, args)
+			if err != nil {
+				return nil, err
+			}
+			return results[0], nil
+		}
+	}
+	return nil
+}
+
+// bindObject binds object to the Go type of its values. Values held in Go interfaces and maps
+// are not bound, the fields of maps are resolved with their entries.
+func (b *sdlBuilder) bindObject(object *internal.Object, goType reflect.Type) error {
+	switch goType.Kind() {
+	case reflect.Interface:
+		return nil
+	case reflect.Map:
+		if goType.Key().Kind() == reflect.String {
+			return nil
+		}
+	case reflect.Struct:
+		if bound, ok := b.goTypes[object.Name]; ok {
+			if bound != goType {
+				return fmt.Errorf("%s is bound to both %s and %s", object.Name, bound, goType)
+			}
+			return nil
+		}
+		b.goTypes[object.Name] = goType
+		object.IsTypeOf = reflect.Zero(goType).Interface()
+		return nil
+	}
+	return fmt.Errorf("%s can not hold values of %s", goType, object.Name)
+}
+
+// bindOutput checks that values of goType can be returned as values of typ, and binds the objects
+// to the Go types of their values. Nullability is not checked, nil values of non null types fail at execution.
+func (b *sdlBuilder) bindOutput(goType reflect.Type, typ internal.Type) error {
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		typ = nonNull.Type
+	}
+	goType = indirect(goType)
+	if goType.Kind() == reflect.Interface {
+		return nil
+	}
+	switch typ := typ.(type) {
+	case *internal.List:
+		if goType.Kind() != reflect.Slice && goType.Kind() != reflect.Array {
+			break
+		}
+		return b.bindOutput(goType.Elem(), typ.Type)
+	case *internal.Scalar:
+		if b.scalarHolds(typ.Name, goType, false) {
+			return nil
+		}
+	case *internal.Enum:
+		return b.bindEnum(goType, typ)
+	case *internal.Object:
+		return b.bindObject(typ, goType)
+	case *internal.Interface:
+		// the objects are told apart by the Go types they are bound to
+		if goType.Kind() == reflect.Struct {
+			return nil
+		}
+	case *internal.Union:
+		if goType.Kind() != reflect.Struct {
+			break
+		}
+		for _, name := range sortedKeys(typ.Types) {
+			field, ok := structField(goType, name)
+			if !ok {
+				return fmt.Errorf("%s has no field for the member %s of %s", goType, name, typ.Name)
+			}
+			if field.Type.Kind() != reflect.Ptr && field.Type.Kind() != reflect.Interface {
+				return fmt.Errorf("the field %s of %s must be a pointer", field.Name, goType)
+			}
+			if err := b.bindOutput(field.Type, typ.Types[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s can not hold values of %s", goType, typ)
+}
+
+// bindInput checks that values of typ can be converted to goType, and sets up their conversion.
+func (b *sdlBuilder) bindInput(goType reflect.Type, typ internal.Type) error {
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		typ = nonNull.Type
+	}
+	goType = indirect(goType)
+	switch typ := typ.(type) {
+	case *internal.List:
+		if goType.Kind() != reflect.Slice {
+			break
+		}
+		return b.bindInput(goType.Elem(), typ.Type)
+	case *internal.Scalar:
+		if b.scalarHolds(typ.Name, goType, true) {
+			return nil
+		}
+	case *internal.Enum:
+		return b.bindEnum(goType, typ)
+	case *internal.InputObject:
+		return b.bindStruct(goType, typ.Fields, typ.Name)
+	}
+	return fmt.Errorf("%s can not hold values of %s", goType, typ)
+}
+
+// bindStruct checks that the struct goType has a field for each of fields, and no other, and sets up the
+// conversion of their values into it. The default values of fields are applied by converToStruct, like the
+// default values of code-first input objects, so a struct can only be shared by fields with the same defaults.
+func (b *sdlBuilder) bindStruct(goType reflect.Type, fields map[string]*internal.InputField, owner string) error {
+	goType = indirect(goType)
+	if goType.Kind() != reflect.Struct {
+		return fmt.Errorf("%s can not hold the values of %s, it is not a struct", goType, owner)
+	}
+	defaults := make(map[string]*inputFieldResolve)
+	for name, field := range fields {
+		if field.DefaultValue != nil {
+			defaults[name] = &inputFieldResolve{DefaultValue: field.DefaultValue}
+		}
+	}
+	if bound, ok := b.inputs[goType]; ok {
+		if bound == owner {
+			return nil
+		}
+		if !reflect.DeepEqual(b.sb.inputObjects[goType].Fields, defaults) {
+			return fmt.Errorf("%s can not hold the values of both %s and %s, their default values differ", goType, bound, owner)
+		}
+	} else {
+		b.inputs[goType] = owner
+		b.sb.inputObjects[goType] = &InputObject{Name: owner, Type: reflect.Zero(goType).Interface(), Fields: defaults}
+		b.sb.cacheTypes[goType] = b.sb.converToStruct(goType)
+	}
+
+	goFields := make(map[string]reflect.StructField)
+	for i := 0; i < goType.NumField(); i++ {
+		field := goType.Field(i)
+		skip, _, _, name, _ := parseFieldTag(field)
+		if skip {
+			continue
+		}
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("the field %s of %s is for %s, which %s does not have", field.Name, goType, name, owner)
+		}
+		goFields[name] = field
+	}
+	for _, name := range sortedKeys(fields) {
+		goField, ok := goFields[name]
+		if !ok {
+			return fmt.Errorf("%s has no field for %s of %s", goType, name, owner)
+		}
+		if err := b.bindInput(goField.Type, fields[name].Type); err != nil {
+			return fmt.Errorf("%s of %s: %v", name, owner, err)
+		}
+		if err := b.sb.getArgResolve(goField.Type, fields[name].Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindEnum checks that goType holds the values of enum. The named string types holding the names of the values
+// are added to the values of enum, to be returned like strings.
+func (b *sdlBuilder) bindEnum(goType reflect.Type, enum *internal.Enum) error {
+	if valueType, ok := b.enumValues[enum.Name]; ok {
+		if goType != valueType {
+			return fmt.Errorf("%s can not hold values of %s, whose Go values are of %s", goType, enum.Name, valueType)
+		}
+		return nil
+	}
+	if goType.Kind() != reflect.String {
+		return fmt.Errorf("%s can not hold values of %s", goType, enum.Name)
+	}
+	if goType.PkgPath() == "" {
+		return nil
+	}
+	if bound, ok := b.enumTypes[goType]; ok {
+		if bound != enum.Name {
+			return fmt.Errorf("%s can not hold the values of both %s and %s", goType, bound, enum.Name)
+		}
+		return nil
+	}
+	b.enumTypes[goType] = enum.Name
+	for _, name := range enum.Values {
+		enum.Map[reflect.ValueOf(name).Convert(goType).Interface()] = name
+	}
+	return nil
+}
+
+// scalarHolds tells whether goType holds values of the scalar name. Ints can be returned as floats
+// and IDs, which are taken as strings.
+func (b *sdlBuilder) scalarHolds(name string, goType reflect.Type, input bool) bool {
+	kind := goType.Kind()
+	isInt := kind >= reflect.Int && kind <= reflect.Uint64
+	switch name {
+	case "Int":
+		return isInt
+	case "Float":
+		return kind == reflect.Float32 || kind == reflect.Float64 || !input && isInt
+	case "String":
+		return kind == reflect.String
+	case "Boolean":
+		return kind == reflect.Bool
+	case "ID":
+		return kind == reflect.String || !input && (isInt || goType == reflect.TypeOf(Id{}))
+	}
+	scalar := b.scalars[name]
+	if scalar.Type == nil {
+		return true
+	}
+	scalarType := reflect.TypeOf(scalar.Type)
+	return goType == scalarType || goType.Kind() == scalarType.Kind() && goType.ConvertibleTo(scalarType)
+}
+
+// bindStructFields resolves the fields without function with the fields of the Go types of their objects.
+// Binding the fields binds the objects they return, so this goes on until every bound object is done.
+func (b *sdlBuilder) bindStructFields() error {
+	done := make(map[string]bool)
+	for len(done) < len(b.goTypes) {
+		for _, name := range sortedKeys(b.goTypes) {
+			if done[name] {
+				continue
+			}
+			done[name] = true
+			object, goType := b.types[name].(*internal.Object), b.goTypes[name]
+			for _, fieldName := range sortedKeys(object.Fields) {
+				field := object.Fields[fieldName]
+				if field.Resolve != nil {
+					continue
+				}
+				structField, ok := structField(goType, fieldName)
+				if !ok {
+					return fmt.Errorf("%s.%s has no resolver and %s has no field for it", name, fieldName, goType)
+				}
+				if err := b.bindOutput(structField.Type, field.Type); err != nil {
+					return fmt.Errorf("%s.%s: %v", name, fieldName, err)
+				}
+				field.Resolve = structFieldResolve(fieldName)
+			}
+		}
+	}
+	for _, typ := range b.types {
+		if object, ok := typ.(*internal.Object); ok {
+			for name, field := range object.Fields {
+				if field.Resolve == nil {
+					field.Resolve = structFieldResolve(name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveInterfaces resolves the objects of the interfaces by the Go types they are bound to.
+func (b *sdlBuilder) resolveInterfaces() error {
+	for _, name := range sortedKeys(b.types) {
+		iface, ok := b.types[name].(*internal.Interface)
+		if !ok {
+			continue
+		}
+		objects := make(map[reflect.Type]*internal.Object, len(iface.PossibleTypes))
+		for _, objectName := range sortedKeys(iface.PossibleTypes) {
+			goType, ok := b.goTypes[objectName]
+			if !ok {
+				return fmt.Errorf("%s can not be told apart from the other types of %s, give a value of its Go type as resolvers[%q]",
+					objectName, name, objectName)
+			}
+			if other, ok := objects[goType]; ok {
+				return fmt.Errorf("%s and %s of %s can not be told apart, they are bound to %s", other.Name, objectName, name, goType)
+			}
+			objects[goType] = iface.PossibleTypes[objectName]
+		}
+		iface.TypeResolve = func(ctx context.Context, value interface{}) *internal.Object {
+			typ := reflect.TypeOf(value)
+			if typ == nil {
+				return nil
+			}
+			return objects[indirect(typ)]
+		}
+	}
+	return nil
+}
+
+// buildDirectives builds the directives of the schema. Those used in queries need a function,
+// whose arguments must match the definition.
+func (b *sdlBuilder) buildDirectives() (map[string]*internal.Directive, error) {
+	directives := make(map[string]*internal.Directive)
+	for _, directive := range []*Directive{IncludeDirective, SkipDirective, DeferDirective, StreamDirective} {
+		built, err := b.sb.getDirective(directive)
+		if err != nil {
+			return nil, err
+		}
+		directives[directive.Name] = built
+	}
+	for _, name := range sortedKeys(b.directives) {
+		if specifiedDirectives[name] {
+			continue
+		}
+		definition := b.directives[name]
+		args, err := b.inputValues("@"+name, definition.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		directive := &internal.Directive{
+			Name:       name,
+			Desc:       description(definition.Desc),
+			Args:       args,
+			Locs:       definition.Locations,
+			Repeatable: definition.Repeatable,
+		}
+		fn, ok := b.resolvers["@"+name]
+		if !ok {
+			for _, location := range definition.Locations {
+				if executableLocations[location] {
+					return nil, fmt.Errorf("directive @%s has no implementation, give its function as resolvers[%q]", name, "@"+name)
+				}
+			}
+			directives[name] = directive
+			continue
+		}
+		implemented, err := b.sb.getDirective(&Directive{Name: name, Fn: fn, Locs: definition.Locations})
+		if err != nil {
+			return nil, fmt.Errorf("@%s: %v", name, err)
+		}
+		for _, argName := range sortedKeys(implemented.Args) {
+			arg, ok := args[argName]
+			if !ok {
+				return nil, fmt.Errorf("@%s: the function takes the argument %s, which is not defined", name, argName)
+			}
+			if goArg := implemented.Args[argName]; goArg.Type.String() != arg.Type.String() {
+				return nil, fmt.Errorf("@%s: the argument %s is %s, but the function takes %s", name, argName, arg.Type, goArg.Type)
+			}
+		}
+		for _, argName := range sortedKeys(args) {
+			if _, ok := implemented.Args[argName]; !ok {
+				return nil, fmt.Errorf("@%s: the function does not take the argument %s", name, argName)
+			}
+		}
+		directive.FnResolve = implemented.FnResolve
+		directives[name] = directive
+	}
+	return directives, nil
+}
+
+// structFieldResolve resolves the field name with the struct field of that name, or the map entry, of its source.
+func structFieldResolve(name string) internal.FieldResolve {
+	return func(ctx context.Context, source, args interface{}) (interface{}, error) {
+		value := reflect.ValueOf(source)
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return nil, nil
+			}
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.Invalid:
+			return nil, nil
+		case reflect.Struct:
+			if field, ok := structField(value.Type(), name); ok {
+				return value.FieldByIndex(field.Index).Interface(), nil
+			}
+		case reflect.Map:
+			if key := value.Type().Key(); key.Kind() == reflect.String {
+				if entry := value.MapIndex(reflect.ValueOf(name).Convert(key)); entry.IsValid() {
+					return entry.Interface(), nil
+				}
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("%s has no field %s", value.Type(), name)
+	}
+}
+
+// structField returns the field of the struct typ named name, by its graphql tag or its Go name.
+func structField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if skip, _, _, fieldName, _ := parseFieldTag(field); !skip && fieldName == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func namedType(typ internal.Type) internal.Type {
+	for {
+		switch t := typ.(type) {
+		case *internal.NonNull:
+			typ = t.Type
+		case *internal.List:
+			typ = t.Type
+		default:
+			return typ
+		}
+	}
+}
+
+func typeDefinitionName(definition ast.TypeDefinition) string {
+	switch definition := definition.(type) {
+	case *ast.ScalarDefinition:
+		return definition.Name.Name
+	case *ast.ObjectDefinition:
+		return definition.Name.Name
+	case *ast.InterfaceDefinition:
+		return definition.Name.Name
+	case *ast.UnionDefinition:
+		return definition.Name.Name
+	case *ast.EnumDefinition:
+		return definition.Name.Name
+	case *ast.InputObjectDefinition:
+		return definition.Name.Name
+	}
+	return ""
+}
+
+func description(desc *ast.StringValue) string {
+	if desc == nil {
+		return ""
+	}
+	return desc.Value
+}
+
+// deprecation returns the reason of the @deprecated directive among directives, or nil when there is none.
+func deprecation(directives []*ast.Directive) *string {
+	for _, directive := range directives {
+		if directive.Name.Name != "deprecated" {
+			continue
+		}
+		reason := DefaultDeprecationReason
+		for _, arg := range directive.Args {
+			if value, ok := arg.Value.(*ast.StringValue); ok && arg.Name.Name == "reason" {
+				reason = value.Value
+			}
+		}
+		return &reason
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m, a map keyed by strings, in order.
+func sortedKeys(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.String()
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,344 @@
+package schemabuilder_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const sdlSource = `
+"""A character of the films."""
+interface Character {
+  id: ID!
+  name: String!
+  friends: [Character]
+}
+
+enum Episode { NEWHOPE EMPIRE JEDI }
+
+type Human implements Character {
+  id: ID!
+  name: String!
+  friends: [Character]
+  height(unit: Unit = METER): Float
+}
+
+type Droid implements Character {
+  id: ID!
+  name: String!
+  friends: [Character]
+  primaryFunction: String @deprecated
+}
+
+enum Unit { METER FOOT }
+
+union SearchResult = Human | Droid
+
+input ReviewInput {
+  stars: Int!
+  commentary: String = "none"
+}
+
+type Review {
+  episode: Episode
+  stars: Int!
+  commentary: String
+}
+
+type Query {
+  hero(episode: Episode = NEWHOPE): Character
+  search(text: String!): [SearchResult]
+}
+
+extend type Query {
+  reviews: [Review!]!
+}
+
+type Mutation {
+  createReview(episode: Episode!, review: ReviewInput!): Review
+}
+
+directive @upper on FIELD
+`
+
+type sdlHuman struct {
+	ID      string   `graphql:"id"`
+	Name    string   `graphql:"name"`
+	Height  float64  `graphql:"-"`
+	Friends []string `graphql:"-"`
+}
+
+type sdlDroid struct {
+	ID              string `graphql:"id"`
+	Name            string `graphql:"name"`
+	PrimaryFunction string `graphql:"primaryFunction"`
+	Friends         []string
+}
+
+type sdlSearchResult struct {
+	Human *sdlHuman
+	Droid *sdlDroid
+}
+
+type sdlReview struct {
+	Episode    string `graphql:"episode"`
+	Stars      int    `graphql:"stars"`
+	Commentary string `graphql:"commentary"`
+}
+
+type sdlReviewInput struct {
+	Stars      int    `graphql:"stars"`
+	Commentary string `graphql:"commentary"`
+}
+
+var (
+	luke  = &sdlHuman{ID: "1000", Name: "Luke", Height: 2, Friends: []string{"2001"}}
+	r2d2  = &sdlDroid{ID: "2001", Name: "R2-D2", PrimaryFunction: "Astromech", Friends: []string{"1000"}}
+	sdlDB = map[string]interface{}{"1000": luke, "2001": r2d2}
+)
+
+func sdlFriends(ids []string) []interface{} {
+	var friends []interface{}
+	for _, id := range ids {
+		friends = append(friends, sdlDB[id])
+	}
+	return friends
+}
+
+func sdlResolvers(reviews *[]sdlReview) schemabuilder.Resolvers {
+	return schemabuilder.Resolvers{
+		"Query.hero": func(args struct {
+			Episode string `graphql:"episode"`
+		}) interface{} {
+			if args.Episode == "EMPIRE" {
+				return luke
+			}
+			return r2d2
+		},
+		"Query.search": func(ctx context.Context, args struct {
+			Text string `graphql:"text"`
+		}) []sdlSearchResult {
+			var results []sdlSearchResult
+			if strings.Contains(luke.Name, args.Text) {
+				results = append(results, sdlSearchResult{Human: luke})
+			}
+			if strings.Contains(r2d2.Name, args.Text) {
+				results = append(results, sdlSearchResult{Droid: r2d2})
+			}
+			return results
+		},
+		"Query.reviews": func() []sdlReview {
+			return *reviews
+		},
+		"Mutation.createReview": func(args struct {
+			Episode string         `graphql:"episode"`
+			Review  sdlReviewInput `graphql:"review"`
+		}) (*sdlReview, error) {
+			if args.Review.Stars > 5 {
+				return nil, errors.New("too many stars")
+			}
+			review := sdlReview{Episode: args.Episode, Stars: args.Review.Stars, Commentary: args.Review.Commentary}
+			*reviews = append(*reviews, review)
+			return &review, nil
+		},
+		"Human.height": func(human *sdlHuman, args struct {
+			Unit string `graphql:"unit"`
+		}) float64 {
+			if args.Unit == "FOOT" {
+				return human.Height * 3.28
+			}
+			return human.Height
+		},
+		"Human.friends": func(humans []*sdlHuman) [][]interface{} {
+			friends := make([][]interface{}, len(humans))
+			for i, human := range humans {
+				friends[i] = sdlFriends(human.Friends)
+			}
+			return friends
+		},
+		"Droid.friends": func(droid sdlDroid) []interface{} {
+			return sdlFriends(droid.Friends)
+		},
+		"@upper": func(field func() (interface{}, error)) (interface{}, error) {
+			result, err := field()
+			if s, ok := result.(string); ok {
+				return strings.ToUpper(s), err
+			}
+			return result, err
+		},
+	}
+}
+
+func TestBuildSDL(t *testing.T) {
+	var reviews []sdlReview
+	schema, err := schemabuilder.BuildSDL(sdlSource, sdlResolvers(&reviews))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, test := range []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "default argument",
+			query:    `{hero{id name ...droid}} fragment droid on Droid{primaryFunction}`,
+			expected: `{"hero":{"id":"2001","name":"R2-D2","primaryFunction":"Astromech"}}`,
+		},
+		{
+			name:     "enum argument and batch field",
+			query:    `{hero(episode: EMPIRE){name ...human}} fragment human on Human{height(unit: FOOT) friends{name}}`,
+			expected: `{"hero":{"name":"Luke","height":6.56,"friends":[{"name":"R2-D2"}]}}`,
+		},
+		{
+			name:     "union",
+			query:    `{search(text: "u"){__typename ...human}} fragment human on Human{name}`,
+			expected: `{"search":[{"__typename":"Human","name":"Luke"}]}`,
+		},
+		{
+			name:     "directive",
+			query:    `{hero(episode: EMPIRE){name @upper}}`,
+			expected: `{"hero":{"name":"LUKE"}}`,
+		},
+		{
+			name:     "input object default",
+			query:    `mutation{createReview(episode: JEDI, review: {stars: 4}){episode stars commentary}}`,
+			expected: `{"createReview":{"episode":"JEDI","stars":4,"commentary":"none"}}`,
+		},
+		{
+			name:     "extension",
+			query:    `{reviews{episode stars}}`,
+			expected: `{"reviews":[{"episode":"JEDI","stars":4}]}`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			result, errs := execution.Do(schema, execution.Params{Query: test.query})
+			if !assert.Empty(t, errs) {
+				return
+			}
+			actual, _ := json.Marshal(result)
+			assert.JSONEq(t, test.expected, string(actual))
+		})
+	}
+}
+
+func TestBuildSDLErrors(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		resolvers func(schemabuilder.Resolvers)
+		err       string
+	}{
+		{
+			name: "wrong return type",
+			resolvers: func(resolvers schemabuilder.Resolvers) {
+				resolvers["Query.reviews"] = func() []string { return nil }
+			},
+			err: "Query.reviews: string can not hold values of Review",
+		},
+		{
+			name: "missing argument",
+			resolvers: func(resolvers schemabuilder.Resolvers) {
+				resolvers["Query.search"] = func(args struct{}) []sdlSearchResult { return nil }
+			},
+			err: "Query.search: struct {} has no field for text of Query.search",
+		},
+		{
+			name: "wrong argument type",
+			resolvers: func(resolvers schemabuilder.Resolvers) {
+				resolvers["Query.search"] = func(args struct {
+					Text int `graphql:"text"`
+				}) []sdlSearchResult {
+					return nil
+				}
+			},
+			err: "Query.search: text of Query.search: int can not hold values of String",
+		},
+		{
+			name: "missing resolver",
+			resolvers: func(resolvers schemabuilder.Resolvers) {
+				delete(resolvers, "Query.reviews")
+			},
+			err: "Query.reviews has no resolver and schemabuilder.Query has no field for it",
+		},
+		{
+			name: "unknown field",
+			resolvers: func(resolvers schemabuilder.Resolvers) {
+				resolvers["Query.villain"] = func() string { return "" }
+			},
+			err: "resolver Query.villain is not for a field of an object",
+		},
+		{
+			name: "unbound object",
+			resolvers: func(resolvers schemabuilder.Resolvers) {
+				resolvers["Human.friends"] = func(human map[string]interface{}) []interface{} { return nil }
+				resolvers["Human.height"] = func(human map[string]interface{}, args struct {
+					Unit string `graphql:"unit"`
+				}) float64 {
+					return 0
+				}
+				resolvers["Query.search"] = func(args struct {
+					Text string `graphql:"text"`
+				}) []interface{} {
+					return nil
+				}
+			},
+			err: `Human can not be told apart from the other types of Character, give a value of its Go type as resolvers["Human"]`,
+		},
+		{
+			name: "missing directive",
+			resolvers: func(resolvers schemabuilder.Resolvers) {
+				delete(resolvers, "@upper")
+			},
+			err: `directive @upper has no implementation, give its function as resolvers["@upper"]`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resolvers := sdlResolvers(new([]sdlReview))
+			test.resolvers(resolvers)
+			_, err := schemabuilder.BuildSDL(sdlSource, resolvers)
+			if assert.Error(t, err) {
+				assert.Equal(t, test.err, err.Error())
+			}
+		})
+	}
+}
+
+type sdlKey string
+
+type sdlAccount struct {
+	ID     int    `graphql:"id"`
+	Number uint8  `graphql:"number"`
+	Key    sdlKey `graphql:"key"`
+}
+
+func TestBuildSDLIDs(t *testing.T) {
+	schema, err := schemabuilder.BuildSDL(`
+type Account {
+  id: ID!
+  number: ID!
+  key: ID!
+}
+
+type Query {
+  account: Account
+}
+`, schemabuilder.Resolvers{
+		"Account":       sdlAccount{},
+		"Query.account": func() *sdlAccount { return &sdlAccount{ID: 42, Number: 7, Key: "k"} },
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, errs := execution.Do(schema, execution.Params{Query: `{account{id number key}}`})
+	if !assert.Empty(t, errs) {
+		return
+	}
+	actual, _ := json.Marshal(result)
+	assert.JSONEq(t, `{"account":{"id":"42","number":"7","key":"k"}}`, string(actual))
+}
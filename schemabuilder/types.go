@@ -83,6 +83,33 @@ var NonNullField afterBuildFunc = func(param buildParam) error {
 	return nil
 }
 
+// Cost sets the cost of a field for the cost analysis of queries, instead of the default
+// cost of 1 for fields returning objects and 0 for the others.
+func Cost(cost int) afterBuildFunc {
+	return func(param buildParam) error {
+		if cost < 0 {
+			return fmt.Errorf("cost %d of a field can not be negative", cost)
+		}
+		param.f.Cost = &cost
+		return nil
+	}
+}
+
+// DefaultDeprecationReason is the reason of the deprecations which don't give one.
+const DefaultDeprecationReason = internal.DefaultDeprecationReason
+
+// Deprecated marks a field as deprecated for reason, the default reason is used when it is empty.
+// The field still resolves, but introspection reports it as deprecated.
+func Deprecated(reason string) afterBuildFunc {
+	return func(param buildParam) error {
+		if reason == "" {
+			reason = DefaultDeprecationReason
+		}
+		param.f.DeprecationReason = &reason
+		return nil
+	}
+}
+
 // Enum is a representation of an enum that includes both the mapping and reverse mapping.
 type Enum struct {
 	Name       string
@@ -91,6 +118,8 @@ type Enum struct {
 	Map        map[string]interface{}
 	ReverseMap map[interface{}]string
 	DescMap    map[string]string
+	// DeprecatedMap holds the deprecation reasons of the deprecated values
+	DeprecatedMap map[string]string
 }
 
 // Interface is a representation of graphql interface
@@ -148,6 +177,13 @@ type Directive struct {
 //        userID, err := db.AddUser(ctx, args.FirstName, args.LastName)
 //        return userID, err
 //    })
+//
+// A function taking a slice of the object instead of a single instance is a batch
+// resolver, called once for all the elements of a list. It returns either a slice
+// with one result per element, in the same order, or a map keyed by element:
+//    user.FieldFunc("manager", func(ctx context.Context, users []*User) (map[*User]*User, error) {
+//        return db.ManagersOf(ctx, users)
+//    })
 func (s *Object) FieldFunc(name string, fn interface{}, options ...interface{}) {
 	if s.FieldResolve == nil {
 		s.FieldResolve = make(map[string]*fieldResolve)
@@ -259,6 +295,20 @@ type fieldResolve struct {
 	executeChain []FieldFuncOption
 }
 
+// copyArgs returns a copy of the arguments of a resolution when handlers may write into them,
+// as the arguments of a selection are shared by every resolution of it, concurrent or cached.
+func (r *fieldResolve) copyArgs(args interface{}) interface{} {
+	argMap, ok := args.(map[string]interface{})
+	if !ok || len(r.handleChain) == 0 {
+		return args
+	}
+	copied := make(map[string]interface{}, len(argMap)+1)
+	for name, value := range argMap {
+		copied[name] = value
+	}
+	return copied
+}
+
 type inputFieldResolve struct {
 	DefaultValue interface{}
 }
@@ -961,3 +1011,41 @@ var SkipDirective = &Directive{
 		"INLINE_FRAGMENT",
 	},
 }
+
+type deferArg struct {
+	If    *bool   `graphql:"if;Deferred when true or undefined."`
+	Label *string `graphql:"label;Unique name identifying the fragment in the subsequent payloads."`
+}
+
+type streamArg struct {
+	If           *bool   `graphql:"if;Streamed when true or undefined."`
+	Label        *string `graphql:"label;Unique name identifying the list in the subsequent payloads."`
+	InitialCount *int    `graphql:"initialCount;Number of items delivered in the initial payload, 0 when undefined."`
+}
+
+// DeferDirective and StreamDirective are executed by the executor itself,
+// their Fn only resolves the field as if they were not there.
+var DeferDirective = &Directive{
+	Name: "defer",
+	Desc: "Directs the executor to deliver this fragment after the rest of the response.",
+	Fn: func(ctx context.Context, args deferArg, fn DirectiveFn) (bool, interface{}, error) {
+		i, err := fn()
+		return true, i, err
+	},
+	Locs: []string{
+		"FRAGMENT_SPREAD",
+		"INLINE_FRAGMENT",
+	},
+}
+
+var StreamDirective = &Directive{
+	Name: "stream",
+	Desc: "Directs the executor to deliver the items of this list after the initialCount first ones one by one.",
+	Fn: func(ctx context.Context, args streamArg, fn DirectiveFn) (bool, interface{}, error) {
+		i, err := fn()
+		return true, i, err
+	},
+	Locs: []string{
+		"FIELD",
+	},
+}
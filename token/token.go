@@ -23,6 +23,8 @@ const (
 	STRING    = scanner.String
 	RAWSTRING = scanner.RawString
 	AMP       = '&'
+	// BLOCK_STRING is a string between triple quotes, text/scanner has no token for it
+	BLOCK_STRING = scanner.Comment - 1
 )
 
 // NAME -> keyword relationship
@@ -0,0 +1,208 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/shyptr/graphql/execution"
+	"github.com/shyptr/graphql/schemabuilder"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// uploadMemory is the size of the files of a request kept in memory. Once it is used up,
+// the files are written to disk.
+const uploadMemory = 10 << 20
+
+// readMultipart reads the operations and files of a request following the GraphQL multipart request spec,
+// https://github.com/jaydenseric/graphql-multipart-request-spec. The parts are read as they come, the
+// operations and map first, then every file, which is put into the variables at each of its paths.
+// The files read are returned even on error, so that they are closed.
+func readMultipart(ctx *Context) (params []execution.Params, batched bool, uploads []multipart.File, err error) {
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		return nil, false, nil, err
+	}
+	operations, err := nextPart(reader, "operations")
+	if err != nil {
+		return nil, false, nil, err
+	}
+	if trimmed := bytes.TrimLeft(operations, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		batched = true
+		err = json.Unmarshal(operations, &params)
+	} else {
+		params = make([]execution.Params, 1)
+		err = json.Unmarshal(operations, &params[0])
+	}
+	if err != nil {
+		return nil, false, nil, fmt.Errorf("invalid operations: %v", err)
+	}
+	mapping, err := nextPart(reader, "map")
+	if err != nil {
+		return nil, false, nil, err
+	}
+	var fileMap map[string][]string
+	if err := json.Unmarshal(mapping, &fileMap); err != nil {
+		return nil, false, nil, fmt.Errorf("invalid map: %v", err)
+	}
+	if ctx.MaxUploads > 0 && len(fileMap) > ctx.MaxUploads {
+		return nil, false, nil, &statusError{http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("a request must not upload more than %d files", ctx.MaxUploads)}
+	}
+
+	memory := int64(uploadMemory)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, uploads, err
+		}
+		paths, ok := fileMap[part.FormName()]
+		if !ok {
+			// files the map doesn't mention are not used
+			part.Close()
+			continue
+		}
+		delete(fileMap, part.FormName())
+		file, size, err := readUpload(part, ctx.MaxUploadSize, &memory)
+		if err != nil {
+			return nil, false, uploads, err
+		}
+		uploads = append(uploads, file)
+		upload := schemabuilder.Upload{File: file, Filename: part.FileName(), Size: size}
+		for _, path := range paths {
+			if err := setUpload(params, batched, path, upload); err != nil {
+				return nil, false, uploads, err
+			}
+		}
+	}
+	for key := range fileMap {
+		return nil, false, uploads, fmt.Errorf("file %q is missing", key)
+	}
+	return params, batched, uploads, nil
+}
+
+// nextPart returns the content of the next part of reader, which must be the field name.
+func nextPart(reader *multipart.Reader, name string) ([]byte, error) {
+	part, err := reader.NextPart()
+	if err != nil {
+		return nil, fmt.Errorf("missing %s: %v", name, err)
+	}
+	defer part.Close()
+	if part.FormName() != name {
+		return nil, fmt.Errorf("expected %s but got %q", name, part.FormName())
+	}
+	return ioutil.ReadAll(part)
+}
+
+// readUpload reads the file of part, in memory when it fits in what is left of memory, which it then
+// takes from, and into a temporary file otherwise. Files larger than maxSize, when positive, are rejected.
+func readUpload(part *multipart.Part, maxSize int64, memory *int64) (multipart.File, int64, error) {
+	tooLarge := &statusError{http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("file %q must not be larger than %d bytes", part.FileName(), maxSize)}
+	var content io.Reader = part
+	if maxSize > 0 {
+		content = io.LimitReader(part, maxSize+1)
+	}
+	var buf bytes.Buffer
+	size, err := io.CopyN(&buf, content, *memory+1)
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+	if maxSize > 0 && size > maxSize {
+		return nil, 0, tooLarge
+	}
+	if size <= *memory {
+		*memory -= size
+		return memoryFile{bytes.NewReader(buf.Bytes())}, size, nil
+	}
+
+	file, err := ioutil.TempFile("", "graphql-upload-")
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err = io.Copy(file, io.MultiReader(&buf, content))
+	if err == nil && maxSize > 0 && size > maxSize {
+		err = tooLarge
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		closeUploads([]multipart.File{file})
+		return nil, 0, err
+	}
+	return file, size, nil
+}
+
+// memoryFile is an uploaded file kept in memory.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// closeUploads closes the uploaded files and removes those written to disk.
+func closeUploads(uploads []multipart.File) {
+	for _, upload := range uploads {
+		upload.Close()
+		if file, ok := upload.(*os.File); ok {
+			os.Remove(file.Name())
+		}
+	}
+}
+
+// setUpload puts upload in the variables of params at path, the dot separated object keys and list
+// indexes leading to it, like variables.files.0, prefixed by the index of the operation in a batch.
+func setUpload(params []execution.Params, batched bool, path string, upload schemabuilder.Upload) error {
+	keys := strings.Split(path, ".")
+	index := 0
+	if batched {
+		var err error
+		if index, err = strconv.Atoi(keys[0]); err != nil || index < 0 || index >= len(params) {
+			return fmt.Errorf("invalid path %q: no operation %s", path, keys[0])
+		}
+		keys = keys[1:]
+	}
+	if len(keys) < 2 || keys[0] != "variables" {
+		return fmt.Errorf("invalid path %q: files can only be variables", path)
+	}
+	if params[index].Variables == nil {
+		params[index].Variables = make(map[string]interface{})
+	}
+
+	var value interface{} = params[index].Variables
+	for i, key := range keys[1:] {
+		last := i == len(keys)-2
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if last {
+				v[key] = upload
+				return nil
+			}
+			value = v[key]
+		case []interface{}:
+			item, err := strconv.Atoi(key)
+			if err != nil || item < 0 || item >= len(v) {
+				return fmt.Errorf("invalid path %q: no item %s", path, key)
+			}
+			if last {
+				v[item] = upload
+				return nil
+			}
+			value = v[item]
+		default:
+			return fmt.Errorf("invalid path %q: %s is neither an object nor a list", path, strings.Join(keys[:i+1], "."))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"strings"
+)
+
+// detectFragmentCycles reports the fragments which spread themselves, directly or through other fragments.
+// Each cycle is reported once, from the first of its fragments in the document.
+func (v *validator) detectFragmentCycles(document *internal.Document) {
+	visited := make(map[string]bool)
+	for _, fragment := range document.Fragments {
+		if v.fragments[fragment.Name.Name] == fragment {
+			v.detectCycles(fragment.Name.Name, visited, nil, make(map[string]int))
+		}
+	}
+}
+
+// detectCycles walks the spreads of the fragment name, path holds the spreads which led to it
+// and indexes their position in path by the name of the fragment spread.
+func (v *validator) detectCycles(name string, visited map[string]bool, path []*ast.FragmentSpread, indexes map[string]int) {
+	if visited[name] {
+		return
+	}
+	visited[name] = true
+	scope, ok := v.fragmentScopes[name]
+	if !ok {
+		return
+	}
+	indexes[name] = len(path)
+	for _, spread := range scope.spreads {
+		spreadName := spread.Name.Name
+		index, ok := indexes[spreadName]
+		if !ok {
+			v.detectCycles(spreadName, visited, append(path, spread), indexes)
+			continue
+		}
+
+		cycle := append(path[index:len(path):len(path)], spread)
+		via := make([]string, 0, len(cycle)-1)
+		locs := make([]errors.Location, 0, len(cycle))
+		for i, s := range cycle {
+			if i < len(cycle)-1 {
+				via = append(via, s.Name.Name)
+			}
+			locs = append(locs, s.Loc)
+		}
+		var err *errors.GraphQLError
+		if len(via) > 0 {
+			err = v.addErr("NoFragmentCycles", locs[0], "Cannot spread fragment %q within itself via %s.", spreadName, strings.Join(via, ", "))
+		} else {
+			err = v.addErr("NoFragmentCycles", locs[0], "Cannot spread fragment %q within itself.", spreadName)
+		}
+		err.Locations = locs
+	}
+	delete(indexes, name)
+}
+
+// detectUnusedFragments reports the fragments which no operation spreads, directly or not.
+func (v *validator) detectUnusedFragments(document *internal.Document) {
+	used := make(map[string]bool)
+	var walk func(s *scope)
+	walk = func(s *scope) {
+		for _, spread := range s.spreads {
+			name := spread.Name.Name
+			if used[name] {
+				continue
+			}
+			used[name] = true
+			if fragment, ok := v.fragmentScopes[name]; ok {
+				walk(fragment)
+			}
+		}
+	}
+	for _, op := range document.Operations {
+		walk(v.operationScopes[op])
+	}
+	for _, fragment := range document.Fragments {
+		if !used[fragment.Name.Name] {
+			v.addErr("NoUnusedFragments", fragment.Loc, "Fragment %q is never used.", fragment.Name.Name)
+		}
+	}
+}
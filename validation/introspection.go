@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection/meta"
+)
+
+// The meta fields and the types of introspection are known to every schema, whether
+// introspection.AddIntrospectionToSchema added them to it or not.
+var (
+	typenameField = &internal.Field{Name: "__typename", Type: &internal.NonNull{Type: &internal.Scalar{Name: "String"}}}
+
+	// metaFields are the meta fields of the query root
+	metaFields, metaTypes = meta.Definitions()
+)
+
+// field returns the definition of the field name of parent, the meta fields included, or nil when it has none.
+func (v *validator) field(parent internal.NamedType, name string) *internal.Field {
+	switch name {
+	case "__typename":
+		return typenameField
+	case "__schema", "__type":
+		if v.schema.Query != nil && parent == v.schema.Query {
+			return metaFields[name]
+		}
+	}
+	return fields(parent)[name]
+}
+
+// lookupType returns the type called name, among the types of introspection as well, or nil when there is none.
+func (v *validator) lookupType(name string) internal.NamedType {
+	if typ, ok := metaTypes[name]; ok {
+		return typ
+	}
+	return v.schema.TypeMap[name]
+}
@@ -0,0 +1,188 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"strings"
+)
+
+// fieldDef is a field selected on a value of type parent, def is nil when the field is unknown.
+type fieldDef struct {
+	parent internal.NamedType
+	field  *ast.Field
+	def    *internal.Field
+}
+
+type fieldPair struct {
+	a, b *ast.Field
+}
+
+type conflict struct {
+	responseName string
+	reason       string
+	locs         []errors.Location
+}
+
+// detectConflicts reports the fields of set, and of the fragments it spreads, which share a response
+// name but could not be merged into one.
+func (v *validator) detectConflicts(parent internal.NamedType, set *ast.SelectionSet) {
+	fieldMap, names := v.collectFields(parent, set)
+	for _, name := range names {
+		defs := fieldMap[name]
+		for i := 0; i < len(defs); i++ {
+			for j := i + 1; j < len(defs); j++ {
+				if c := v.findConflict(false, name, defs[i], defs[j]); c != nil {
+					err := v.addErr("OverlappingFieldsCanBeMerged", c.locs[0],
+						"Fields %q conflict because %s. Use different aliases on the fields to fetch both if this was intentional.",
+						c.responseName, c.reason)
+					err.Locations = c.locs
+				}
+			}
+		}
+	}
+}
+
+// collectFields returns the fields selected by set, through its fragments too, by response name,
+// with the response names in the order they are first selected.
+func (v *validator) collectFields(parent internal.NamedType, set *ast.SelectionSet) (map[string][]*fieldDef, []string) {
+	fieldMap := make(map[string][]*fieldDef)
+	var names []string
+	visited := make(map[string]bool)
+	var collect func(parent internal.NamedType, set *ast.SelectionSet)
+	collect = func(parent internal.NamedType, set *ast.SelectionSet) {
+		for _, selection := range set.Selections {
+			switch selection := selection.(type) {
+			case *ast.Field:
+				name := selection.Name.Name
+				if selection.Alias != nil {
+					name = selection.Alias.Name
+				}
+				if _, ok := fieldMap[name]; !ok {
+					names = append(names, name)
+				}
+				var def *internal.Field
+				if parent != nil {
+					def = v.field(parent, selection.Name.Name)
+				}
+				fieldMap[name] = append(fieldMap[name], &fieldDef{parent: parent, field: selection, def: def})
+
+			case *ast.InlineFragment:
+				typ := parent
+				if selection.TypeCondition != nil {
+					typ = v.lookupType(selection.TypeCondition.Name.Name)
+				}
+				collect(typ, selection.SelectionSet)
+
+			case *ast.FragmentSpread:
+				name := selection.Name.Name
+				fragment, ok := v.fragments[name]
+				if !ok || visited[name] {
+					continue
+				}
+				visited[name] = true
+				collect(v.lookupType(fragment.TypeCondition.Name.Name), fragment.SelectionSet)
+			}
+		}
+	}
+	collect(parent, set)
+	return fieldMap, names
+}
+
+// findConflict returns why a and b, selected with the same response name, can't be merged, or nil.
+// exclusive is set when their parents can never be the same object, only their shapes must match then.
+func (v *validator) findConflict(exclusive bool, responseName string, a, b *fieldDef) *conflict {
+	if a.field == b.field {
+		return nil
+	}
+	// a pair compared while not exclusive has been compared as strictly as it can be
+	if wasExclusive, ok := v.compared[fieldPair{a.field, b.field}]; ok && (!wasExclusive || exclusive) {
+		return nil
+	}
+	v.compared[fieldPair{a.field, b.field}] = exclusive
+	v.compared[fieldPair{b.field, a.field}] = exclusive
+
+	_, aObject := a.parent.(*internal.Object)
+	_, bObject := b.parent.(*internal.Object)
+	exclusive = exclusive || (a.parent != b.parent && aObject && bObject)
+	locs := []errors.Location{a.field.Loc, b.field.Loc}
+
+	if !exclusive {
+		if a.field.Name.Name != b.field.Name.Name {
+			return &conflict{responseName, fmt.Sprintf("%q and %q are different fields", a.field.Name.Name, b.field.Name.Name), locs}
+		}
+		if !sameArguments(a.field.Arguments, b.field.Arguments) {
+			return &conflict{responseName, "they have differing arguments", locs}
+		}
+	}
+
+	if a.def != nil && b.def != nil && typesConflict(a.def.Type, b.def.Type) {
+		return &conflict{responseName, fmt.Sprintf("they return conflicting types %q and %q", a.def.Type, b.def.Type), locs}
+	}
+
+	if a.field.SelectionSet == nil || b.field.SelectionSet == nil {
+		return nil
+	}
+	var aType, bType internal.NamedType
+	if a.def != nil {
+		aType = namedType(a.def.Type)
+	}
+	if b.def != nil {
+		bType = namedType(b.def.Type)
+	}
+	aFields, names := v.collectFields(aType, a.field.SelectionSet)
+	bFields, _ := v.collectFields(bType, b.field.SelectionSet)
+	var reasons []string
+	for _, name := range names {
+		for _, aField := range aFields[name] {
+			for _, bField := range bFields[name] {
+				if c := v.findConflict(exclusive, name, aField, bField); c != nil {
+					reasons = append(reasons, fmt.Sprintf("subfields %q conflict because %s", c.responseName, c.reason))
+					locs = append(locs, c.locs...)
+				}
+			}
+		}
+	}
+	if len(reasons) > 0 {
+		return &conflict{responseName, strings.Join(reasons, " and "), locs}
+	}
+	return nil
+}
+
+// typesConflict reports whether fields of types a and b can't be merged whatever their parents.
+func typesConflict(a, b internal.Type) bool {
+	aList, aIsList := a.(*internal.List)
+	bList, bIsList := b.(*internal.List)
+	if aIsList || bIsList {
+		return !aIsList || !bIsList || typesConflict(aList.Type, bList.Type)
+	}
+	aNonNull, aIsNonNull := a.(*internal.NonNull)
+	bNonNull, bIsNonNull := b.(*internal.NonNull)
+	if aIsNonNull || bIsNonNull {
+		return !aIsNonNull || !bIsNonNull || typesConflict(aNonNull.Type, bNonNull.Type)
+	}
+	if isComposite(a) || isComposite(b) {
+		return false
+	}
+	return a.String() != b.String()
+}
+
+func sameArguments(a, b []*ast.Argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, aArg := range a {
+		found := false
+		for _, bArg := range b {
+			if aArg.Name.Name == bArg.Name.Name {
+				found = printValue(aArg.Value) == printValue(bArg.Value)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,435 @@
+// Package validation checks that a query document can be executed against a schema,
+// following the rules of https://spec.graphql.org/June2018/#sec-Validation.
+package validation
+
+import (
+	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/errors"
+	"github.com/shyptr/graphql/internal"
+	"sort"
+	"strings"
+)
+
+// Validate checks document against every validation rule of the spec, and returns all
+// the errors found, in document order, with the rule they break and their locations.
+func Validate(schema *internal.Schema, document *internal.Document) errors.MultiError {
+	v := &validator{
+		schema:          schema,
+		fragments:       make(map[string]*ast.FragmentDefinition),
+		fragmentScopes:  make(map[string]*scope),
+		operationScopes: make(map[*ast.OperationDefinition]*scope),
+		compared:        make(map[fieldPair]bool),
+	}
+	v.validateDocument(document)
+	return v.errs
+}
+
+type validator struct {
+	schema *internal.Schema
+	errs   errors.MultiError
+
+	fragments map[string]*ast.FragmentDefinition
+	// scope collects the spreads and variables of the operation or fragment being walked
+	scope           *scope
+	fragmentScopes  map[string]*scope
+	operationScopes map[*ast.OperationDefinition]*scope
+	// compared are the pairs of fields already checked by OverlappingFieldsCanBeMerged
+	compared map[fieldPair]bool
+}
+
+type scope struct {
+	spreads   []*ast.FragmentSpread
+	variables []*variableUsage
+	defined   []*ast.VariableDefinition
+}
+
+// variableUsage is a variable used where a value of type typ is expected, typ is nil when unknown.
+type variableUsage struct {
+	variable *ast.Variable
+	typ      internal.Type
+	// hasDefault is set when the argument or input field the variable is given to has a default value
+	hasDefault bool
+}
+
+func (v *validator) addErr(rule string, loc errors.Location, format string, a ...interface{}) *errors.GraphQLError {
+	err := &errors.GraphQLError{
+		Message:   fmt.Sprintf(format, a...),
+		Locations: []errors.Location{loc},
+		Rule:      rule,
+	}
+	v.errs = append(v.errs, err)
+	return err
+}
+
+func (v *validator) validateDocument(document *internal.Document) {
+	for _, fragment := range document.Fragments {
+		name := fragment.Name.Name
+		if _, ok := v.fragments[name]; ok {
+			v.addErr("UniqueFragmentNames", fragment.Name.Loc, "There can be only one fragment named %q.", name)
+			continue
+		}
+		v.fragments[name] = fragment
+	}
+
+	operationNames := make(map[string]bool)
+	for _, op := range document.Operations {
+		if op.Name == nil {
+			if len(document.Operations) > 1 {
+				v.addErr("LoneAnonymousOperation", op.Loc, "This anonymous operation must be the only defined operation.")
+			}
+		} else if operationNames[op.Name.Name] {
+			v.addErr("UniqueOperationNames", op.Name.Loc, "There can be only one operation named %q.", op.Name.Name)
+		} else {
+			operationNames[op.Name.Name] = true
+		}
+		v.validateOperation(op)
+	}
+
+	for _, fragment := range document.Fragments {
+		if v.fragments[fragment.Name.Name] == fragment {
+			v.validateFragment(fragment)
+		}
+	}
+
+	v.detectFragmentCycles(document)
+	v.detectUnusedFragments(document)
+	for _, op := range document.Operations {
+		v.validateVariables(op)
+	}
+}
+
+func (v *validator) validateOperation(op *ast.OperationDefinition) {
+	v.scope = &scope{defined: op.Vars}
+	v.operationScopes[op] = v.scope
+
+	var root internal.NamedType
+	switch op.Operation {
+	case ast.Query:
+		root = object(v.schema.Query)
+	case ast.Mutation:
+		root = object(v.schema.Mutation)
+	case ast.Subscription:
+		root = object(v.schema.Subscription)
+	}
+	if root == nil {
+		v.addErr("KnownOperationTypes", op.Loc, "Schema is not configured for %s operations.", strings.ToLower(string(op.Operation)))
+	}
+
+	v.validateVariableDefinitions(op.Vars)
+	v.validateDirectives(op.Directives, string(op.Operation))
+	v.validateSelectionSet(root, op.SelectionSet)
+
+	if op.Operation == ast.Subscription && root != nil {
+		if _, names := v.collectFields(root, op.SelectionSet); len(names) != 1 {
+			if op.Name != nil {
+				v.addErr("SingleFieldSubscriptions", op.Loc, "Subscription %q must select only one top level field.", op.Name.Name)
+			} else {
+				v.addErr("SingleFieldSubscriptions", op.Loc, "Anonymous Subscription must select only one top level field.")
+			}
+		}
+	}
+}
+
+func (v *validator) validateFragment(fragment *ast.FragmentDefinition) {
+	v.scope = &scope{defined: fragment.VariableDefinitions}
+	v.fragmentScopes[fragment.Name.Name] = v.scope
+
+	typ := v.typeCondition(fragment.TypeCondition, fragment.Name.Name)
+	v.validateVariableDefinitions(fragment.VariableDefinitions)
+	v.validateDirectives(fragment.Directives, "FRAGMENT_DEFINITION")
+	v.validateSelectionSet(typ, fragment.SelectionSet)
+}
+
+// typeCondition returns the type a fragment conditions on, or nil when it is not a composite type of the schema.
+// fragmentName is empty for inline fragments.
+func (v *validator) typeCondition(condition *ast.Named, fragmentName string) internal.NamedType {
+	typ := v.lookupType(condition.Name.Name)
+	if typ == nil {
+		v.addErr("KnownTypeNames", condition.Loc, "Unknown type %q.", condition.Name.Name)
+		return nil
+	}
+	if !isComposite(typ) {
+		if fragmentName != "" {
+			v.addErr("FragmentsOnCompositeTypes", condition.Loc, "Fragment %q cannot condition on non composite type %q.", fragmentName, typ)
+		} else {
+			v.addErr("FragmentsOnCompositeTypes", condition.Loc, "Fragment cannot condition on non composite type %q.", typ)
+		}
+		return nil
+	}
+	return typ
+}
+
+func (v *validator) validateVariableDefinitions(definitions []*ast.VariableDefinition) {
+	seen := make(map[string]bool)
+	for _, definition := range definitions {
+		name := definition.Var.Name.Name
+		if seen[name] {
+			v.addErr("UniqueVariableNames", definition.Loc, "There can be only one variable named \"$%s\".", name)
+		}
+		seen[name] = true
+
+		typ := v.typeFromAst(definition.Type)
+		if typ == nil {
+			named := namedAst(definition.Type)
+			v.addErr("KnownTypeNames", named.Loc, "Unknown type %q.", named.Name.Name)
+		} else if !internal.IsInputType(typ) {
+			v.addErr("VariablesAreInputTypes", definition.Type.Location(), "Variable \"$%s\" cannot be non-input type %q.", name, definition.Type.String())
+		} else if definition.DefaultValue != nil {
+			v.validateValue(definition.DefaultValue, typ, false)
+		}
+		v.validateDirectives(definition.Directives, "VARIABLE_DEFINITION")
+	}
+}
+
+// validateSelectionSet walks set, selected on a value of type parent. When parent is nil,
+// because a previous error made it unknown, only the rules which don't depend on it are checked.
+func (v *validator) validateSelectionSet(parent internal.NamedType, set *ast.SelectionSet) {
+	if set == nil {
+		return
+	}
+	if parent != nil {
+		v.detectConflicts(parent, set)
+	}
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			v.validateField(parent, selection)
+
+		case *ast.FragmentSpread:
+			name := selection.Name.Name
+			v.validateDirectives(selection.Directives, "FRAGMENT_SPREAD")
+			v.scope.spreads = append(v.scope.spreads, selection)
+			fragment, ok := v.fragments[name]
+			if !ok {
+				v.addErr("KnownFragmentNames", selection.Name.Loc, "Unknown fragment %q.", name)
+				continue
+			}
+			if typ := v.lookupType(fragment.TypeCondition.Name.Name); parent != nil && isComposite(typ) && !overlap(parent, typ) {
+				v.addErr("PossibleFragmentSpreads", selection.Loc,
+					"Fragment %q cannot be spread here as objects of type %q can never be of type %q.", name, parent, typ)
+			}
+
+		case *ast.InlineFragment:
+			v.validateDirectives(selection.Directives, "INLINE_FRAGMENT")
+			typ := parent
+			if selection.TypeCondition != nil {
+				typ = v.typeCondition(selection.TypeCondition, "")
+				if typ != nil && parent != nil && !overlap(parent, typ) {
+					v.addErr("PossibleFragmentSpreads", selection.Loc,
+						"Fragment cannot be spread here as objects of type %q can never be of type %q.", parent, typ)
+				}
+			}
+			v.validateSelectionSet(typ, selection.SelectionSet)
+		}
+	}
+}
+
+func (v *validator) validateField(parent internal.NamedType, field *ast.Field) {
+	name := field.Name.Name
+	var definition *internal.Field
+	if parent != nil {
+		definition = v.field(parent, name)
+		if definition == nil {
+			v.addErr("FieldsOnCorrectType", field.Loc, "Cannot query field %q on type %q.", name, parent)
+		}
+	}
+
+	var args map[string]*internal.InputField
+	if definition != nil {
+		args = definition.Args
+	}
+	v.validateArguments(field.Arguments, args, definition != nil, fmt.Sprintf("Field %q", name), field.Loc)
+	v.validateDirectives(field.Directives, "FIELD")
+
+	if definition == nil {
+		v.validateSelectionSet(nil, field.SelectionSet)
+		return
+	}
+	typ := definition.Type
+	named := namedType(typ)
+	if isComposite(named) {
+		if field.SelectionSet == nil {
+			v.addErr("ScalarLeafs", field.Loc, "Field %q of type %q must have a selection of subfields. Did you mean \"%s { ... }\"?", name, typ, name)
+		}
+		v.validateSelectionSet(named, field.SelectionSet)
+	} else if field.SelectionSet != nil {
+		v.addErr("ScalarLeafs", field.SelectionSet.Loc, "Field %q must not have a selection since type %q has no subfields.", name, typ)
+		v.validateSelectionSet(nil, field.SelectionSet)
+	}
+}
+
+// validateArguments checks the arguments given to owner, a field or a directive,
+// against their definitions. Nothing but the arguments themselves is checked when the definitions are unknown.
+func (v *validator) validateArguments(args []*ast.Argument, definitions map[string]*internal.InputField, known bool,
+	owner string, loc errors.Location) {
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		name := arg.Name.Name
+		if seen[name] {
+			v.addErr("UniqueArgumentNames", arg.Loc, "There can be only one argument named %q.", name)
+		}
+		seen[name] = true
+
+		var typ internal.Type
+		var hasDefault bool
+		if known {
+			if definition := definitions[name]; definition == nil {
+				v.addErr("KnownArgumentNames", arg.Loc, "Unknown argument %q on %s.", name, strings.ToLower(owner[:1])+owner[1:])
+			} else {
+				typ, hasDefault = definition.Type, definition.DefaultValue != nil
+			}
+		}
+		v.validateValue(arg.Value, typ, hasDefault)
+	}
+	if !known {
+		return
+	}
+	for _, name := range sortedKeys(definitions) {
+		definition := definitions[name]
+		if _, ok := definition.Type.(*internal.NonNull); ok && definition.DefaultValue == nil && !seen[name] {
+			v.addErr("ProvidedRequiredArguments", loc, "%s argument %q of type %q is required, but it was not provided.", owner, name, definition.Type)
+		}
+	}
+}
+
+func (v *validator) validateDirectives(directives []*ast.Directive, location string) {
+	seen := make(map[string]bool)
+	for _, directive := range directives {
+		name := directive.Name.Name
+		definition, ok := v.schema.Directives[name]
+		if !ok {
+			v.addErr("KnownDirectives", directive.Loc, "Unknown directive \"@%s\".", name)
+			v.validateArguments(directive.Args, nil, false, "", directive.Loc)
+			continue
+		}
+		if !contains(definition.Locs, location) {
+			v.addErr("KnownDirectives", directive.Loc, "Directive \"@%s\" may not be used on %s.", name, location)
+		}
+		if seen[name] {
+			v.addErr("UniqueDirectivesPerLocation", directive.Loc, "The directive \"@%s\" can only be used once at this location.", name)
+		}
+		seen[name] = true
+		v.validateArguments(directive.Args, definition.Args, true, fmt.Sprintf("Directive \"@%s\"", name), directive.Loc)
+	}
+}
+
+// typeFromAst returns the schema type of typ, or nil when its named type is unknown.
+func (v *validator) typeFromAst(typ ast.Type) internal.Type {
+	switch typ := typ.(type) {
+	case *ast.Named:
+		if named := v.lookupType(typ.Name.Name); named != nil {
+			return named
+		}
+	case *ast.List:
+		if inner := v.typeFromAst(typ.Type); inner != nil {
+			return &internal.List{Type: inner}
+		}
+	case *ast.NonNull:
+		if inner := v.typeFromAst(typ.Type); inner != nil {
+			return &internal.NonNull{Type: inner}
+		}
+	}
+	return nil
+}
+
+func namedAst(typ ast.Type) *ast.Named {
+	for {
+		switch t := typ.(type) {
+		case *ast.List:
+			typ = t.Type
+		case *ast.NonNull:
+			typ = t.Type
+		default:
+			return t.(*ast.Named)
+		}
+	}
+}
+
+// object returns typ as an object, or nil when the schema has no such root type.
+func object(typ internal.Type) internal.NamedType {
+	if object, ok := typ.(*internal.Object); ok && object != nil {
+		return object
+	}
+	return nil
+}
+
+func namedType(typ internal.Type) internal.NamedType {
+	for {
+		switch t := typ.(type) {
+		case *internal.List:
+			typ = t.Type
+		case *internal.NonNull:
+			typ = t.Type
+		case internal.NamedType:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+func fields(typ internal.Type) map[string]*internal.Field {
+	switch typ := typ.(type) {
+	case *internal.Object:
+		return typ.Fields
+	case *internal.Interface:
+		return typ.Fields
+	}
+	return nil
+}
+
+func isComposite(typ internal.Type) bool {
+	switch typ.(type) {
+	case *internal.Object, *internal.Interface, *internal.Union:
+		return true
+	}
+	return false
+}
+
+// possibleTypes returns the names of the objects a value of typ can be.
+func possibleTypes(typ internal.NamedType) map[string]bool {
+	names := make(map[string]bool)
+	switch typ := typ.(type) {
+	case *internal.Object:
+		names[typ.Name] = true
+	case *internal.Interface:
+		for _, object := range typ.PossibleTypes {
+			names[object.Name] = true
+		}
+	case *internal.Union:
+		for _, object := range typ.Types {
+			names[object.Name] = true
+		}
+	}
+	return names
+}
+
+// overlap reports whether some object can be of both types.
+func overlap(a, b internal.NamedType) bool {
+	possible := possibleTypes(a)
+	for name := range possibleTypes(b) {
+		if possible[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]*internal.InputField) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,336 @@
+package validation_test
+
+import (
+	"github.com/shyptr/graphql/internal"
+	"github.com/shyptr/graphql/introspection"
+	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Pet interface {
+	GetName() string
+}
+
+type Dog struct {
+	Name     string `graphql:"name"`
+	Nickname string `graphql:"nickname"`
+}
+
+func (d Dog) GetName() string { return d.Name }
+
+type Cat struct {
+	Name   string `graphql:"name"`
+	Meowed bool   `graphql:"meowed"`
+}
+
+func (c Cat) GetName() string { return c.Name }
+
+type CatOrDog struct {
+	*Cat
+	*Dog
+}
+
+type Color int
+
+type Filter struct {
+	Name  string `graphql:"name"`
+	Color *Color `graphql:"color"`
+}
+
+func testSchema() *internal.Schema {
+	build := schemabuilder.NewSchema()
+	build.Enum("Color", Color(0), map[string]interface{}{"RED": Color(0), "GREEN": Color(1)}, "")
+	build.InputObject("Filter", Filter{}, "")
+
+	pet := build.Interface("Pet", new(Pet), nil, "")
+	pet.FieldFunc("name", "GetName", "")
+	dog := build.Object("Dog", Dog{}, "")
+	dog.InterfaceList(pet)
+	dog.FieldFunc("barks", func(args struct {
+		Loud bool `graphql:"loud"`
+	}) bool {
+		return args.Loud
+	}, "")
+	build.Object("Cat", Cat{}).InterfaceList(pet)
+	build.Union("CatOrDog", CatOrDog{}, "")
+
+	query := build.Query()
+	query.FieldFunc("dog", func(args struct {
+		ID int `graphql:"id"`
+	}) *Dog {
+		return nil
+	}, "")
+	query.FieldFunc("pets", func() []Pet { return nil }, "")
+	query.FieldFunc("catOrDog", func() *CatOrDog { return nil }, "")
+	query.FieldFunc("find", func(args struct {
+		Filter *Filter `graphql:"filter"`
+		Limit  *int    `graphql:"limit"`
+	}) []*Dog {
+		return nil
+	}, "")
+	query.FieldFunc("colors", func(args struct {
+		Color *Color `graphql:"color"`
+	}) []Color {
+		return nil
+	}, "")
+	subscription := build.Subscription()
+	subscription.FieldFunc("dogAdded", func() *Dog { return nil }, "")
+	subscription.FieldFunc("catAdded", func() *Cat { return nil }, "")
+	schema := build.MustBuild()
+	introspection.AddIntrospectionToSchema(schema)
+	return schema
+}
+
+func TestValidate(t *testing.T) {
+	schema := testSchema()
+
+	tests := []struct {
+		name  string
+		query string
+		// errors are given as "Rule: message"
+		errors []string
+	}{
+		{
+			name: "valid query",
+			query: `
+				query ($id: Int!, $loud: Boolean = true) {
+					dog(id: $id) { ...dog barks(loud: $loud) }
+					pets { name ... on Dog { nickname } ... on Cat { meowed } }
+					catOrDog { __typename ... on Pet { name } }
+					find(filter: {name: "Odie", color: GREEN}, limit: 1) { name }
+					colors(color: RED)
+				}
+				fragment dog on Dog { name @skip(if: false) }`,
+		},
+		{
+			name:  "introspection query",
+			query: introspection.IntrospectionQuery,
+		},
+		{
+			name: "operations",
+			query: `
+				{ dog(id: 1) { name } }
+				query a { dog(id: 1) { name } }
+				query a { dog(id: 1) { name } }
+				mutation { a }`,
+			errors: []string{
+				"LoneAnonymousOperation: This anonymous operation must be the only defined operation.",
+				`UniqueOperationNames: There can be only one operation named "a".`,
+				"LoneAnonymousOperation: This anonymous operation must be the only defined operation.",
+				"KnownOperationTypes: Schema is not configured for mutation operations.",
+			},
+		},
+		{
+			name:   "single field subscriptions",
+			query:  `subscription s { dogAdded { name } catAdded { name } }`,
+			errors: []string{`SingleFieldSubscriptions: Subscription "s" must select only one top level field.`},
+		},
+		{
+			name:  "fields",
+			query: `{ dog(id: 1) { unknown barks(loud: true) { name } } pets }`,
+			errors: []string{
+				`FieldsOnCorrectType: Cannot query field "unknown" on type "Dog".`,
+				`ScalarLeafs: Field "barks" must not have a selection since type "Boolean!" has no subfields.`,
+				`ScalarLeafs: Field "pets" of type "[Pet]" must have a selection of subfields. Did you mean "pets { ... }"?`,
+			},
+		},
+		{
+			name:  "arguments",
+			query: `{ dog(id: 1, id: 2, unknown: 3) { barks } }`,
+			errors: []string{
+				`UniqueArgumentNames: There can be only one argument named "id".`,
+				`KnownArgumentNames: Unknown argument "unknown" on field "dog".`,
+				`ProvidedRequiredArguments: Field "barks" argument "loud" of type "Boolean!" is required, but it was not provided.`,
+			},
+		},
+		{
+			name: "values",
+			query: `{
+				a: dog(id: "1") { name }
+				b: dog(id: null) { name }
+				find(filter: {color: BLUE, other: 1, name: "a", name: "b"}) { name }
+				colors(color: "RED")
+			}`,
+			errors: []string{
+				`ValuesOfCorrectType: Expected value of type "Int", found "1".`,
+				`ValuesOfCorrectType: Expected value of type "Int!", found null.`,
+				`UniqueInputFieldNames: There can be only one input field named "name".`,
+				`ValuesOfCorrectType: Value "BLUE" does not exist in "Color" enum.`,
+				`ValuesOfCorrectType: Field "other" is not defined by type "Filter".`,
+				`ValuesOfCorrectType: Enum "Color" cannot represent non-enum value: "RED".`,
+			},
+		},
+		{
+			name:  "required input fields",
+			query: `{ find(filter: {}) { name } }`,
+			errors: []string{
+				`ValuesOfCorrectType: Field "Filter.name" of required type "String!" was not provided.`,
+			},
+		},
+		{
+			name: "directives",
+			query: `query @skip(if: true) {
+				dog(id: 1) @unknown { name @include(if: true) @include(if: false) }
+			}`,
+			errors: []string{
+				`KnownDirectives: Directive "@skip" may not be used on QUERY.`,
+				`KnownDirectives: Unknown directive "@unknown".`,
+				`UniqueDirectivesPerLocation: The directive "@include" can only be used once at this location.`,
+			},
+		},
+		{
+			name: "fragments",
+			query: `
+				{ dog(id: 1) { ...unknown ...onCat ... on Color { name } } }
+				fragment onCat on Cat { name }
+				fragment onCat on Cat { name }
+				fragment unused on Dog { name }
+				fragment onScalar on Boolean { name }
+				fragment onUnknown on Unknown { name }`,
+			errors: []string{
+				`UniqueFragmentNames: There can be only one fragment named "onCat".`,
+				`KnownFragmentNames: Unknown fragment "unknown".`,
+				`PossibleFragmentSpreads: Fragment "onCat" cannot be spread here as objects of type "Dog" can never be of type "Cat".`,
+				`FragmentsOnCompositeTypes: Fragment cannot condition on non composite type "Color".`,
+				`FragmentsOnCompositeTypes: Fragment "onScalar" cannot condition on non composite type "Boolean".`,
+				`KnownTypeNames: Unknown type "Unknown".`,
+				`NoUnusedFragments: Fragment "unused" is never used.`,
+				`NoUnusedFragments: Fragment "onScalar" is never used.`,
+				`NoUnusedFragments: Fragment "onUnknown" is never used.`,
+			},
+		},
+		{
+			name: "fragment cycles",
+			query: `
+				{ dog(id: 1) { ...a } }
+				fragment a on Dog { ...b }
+				fragment b on Dog { ...a }
+				fragment c on Dog { ...c }`,
+			errors: []string{
+				`NoFragmentCycles: Cannot spread fragment "a" within itself via b.`,
+				`NoFragmentCycles: Cannot spread fragment "c" within itself.`,
+				`NoUnusedFragments: Fragment "c" is never used.`,
+			},
+		},
+		{
+			name: "variables",
+			query: `
+				query q($a: Int, $a: Int, $dog: Dog, $unused: Int, $color: Color) {
+					dog(id: $a) { barks(loud: $undefined) }
+					colors(color: $color)
+				}`,
+			errors: []string{
+				`UniqueVariableNames: There can be only one variable named "$a".`,
+				`VariablesAreInputTypes: Variable "$dog" cannot be non-input type "Dog".`,
+				`VariablesInAllowedPosition: Variable "$a" of type "Int" used in position expecting type "Int!".`,
+				`NoUndefinedVariables: Variable "$undefined" is not defined by operation "q".`,
+				`NoUnusedVariables: Variable "$dog" is never used in operation "q".`,
+				`NoUnusedVariables: Variable "$unused" is never used in operation "q".`,
+			},
+		},
+		{
+			name: "variables in fragments",
+			query: `
+				query q($loud: Boolean!) { dog(id: 1) { ...barks } }
+				query r { dog(id: 1) { ...barks } }
+				fragment barks on Dog { barks(loud: $loud) }`,
+			errors: []string{
+				`NoUndefinedVariables: Variable "$loud" is not defined by operation "r".`,
+			},
+		},
+		{
+			name: "overlapping fields",
+			query: `
+				{
+					dog(id: 1) { name: nickname name }
+					a: dog(id: 1) { name }
+					a: dog(id: 2) { name }
+					pets { ... on Dog { n: name } ... on Cat { n: meowed } }
+				}`,
+			errors: []string{
+				`OverlappingFieldsCanBeMerged: Fields "a" conflict because they have differing arguments. ` +
+					`Use different aliases on the fields to fetch both if this was intentional.`,
+				`OverlappingFieldsCanBeMerged: Fields "name" conflict because "nickname" and "name" are different fields. ` +
+					`Use different aliases on the fields to fetch both if this was intentional.`,
+				`OverlappingFieldsCanBeMerged: Fields "n" conflict because they return conflicting types "String!" and "Boolean!". ` +
+					`Use different aliases on the fields to fetch both if this was intentional.`,
+			},
+		},
+		{
+			name: "overlapping subfields",
+			query: `
+				{
+					dog(id: 1) { name }
+					dog(id: 1) { name: nickname }
+				}`,
+			errors: []string{
+				`OverlappingFieldsCanBeMerged: Fields "dog" conflict because subfields "name" conflict because "name" and "nickname" are different fields. ` +
+					`Use different aliases on the fields to fetch both if this was intentional.`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := internal.Parse(test.query)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var errs []string
+			for _, err := range validation.Validate(schema, doc) {
+				assert.NotEmpty(t, err.Locations)
+				errs = append(errs, err.Rule+": "+err.Message)
+			}
+			assert.Equal(t, test.errors, errs)
+		})
+	}
+}
+
+func TestValidateMetaFields(t *testing.T) {
+	build := schemabuilder.NewSchema()
+	build.Object("Dog", Dog{}, "")
+	build.Query().FieldFunc("dog", func() *Dog { return nil }, "")
+	// without introspection.AddIntrospectionToSchema
+	schema := build.MustBuild()
+
+	tests := []struct {
+		name  string
+		query string
+		// errors are given as "Rule: message"
+		errors []string
+	}{
+		{
+			name:  "introspection query",
+			query: introspection.IntrospectionQuery,
+		},
+		{
+			name: "misused meta fields",
+			query: `
+				{
+					__type { kind { name } description }
+					dog { __typename __schema { types { name } } }
+				}`,
+			errors: []string{
+				`ProvidedRequiredArguments: Field "__type" argument "name" of type "String!" is required, but it was not provided.`,
+				`ScalarLeafs: Field "kind" must not have a selection since type "__TypeKind!" has no subfields.`,
+				`FieldsOnCorrectType: Cannot query field "__schema" on type "Dog".`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := internal.Parse(test.query)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var errs []string
+			for _, err := range validation.Validate(schema, doc) {
+				errs = append(errs, err.Rule+": "+err.Message)
+			}
+			assert.Equal(t, test.errors, errs)
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/shyptr/graphql/ast"
+	"github.com/shyptr/graphql/internal"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// validateValue checks the literal value given where a value of type typ is expected,
+// and records the variables it uses. typ is nil when unknown, only the variables are recorded then.
+func (v *validator) validateValue(value ast.Value, typ internal.Type, hasDefault bool) {
+	if variable, ok := value.(*ast.Variable); ok {
+		v.scope.variables = append(v.scope.variables, &variableUsage{variable: variable, typ: typ, hasDefault: hasDefault})
+		return
+	}
+	if object, ok := value.(*ast.ObjectValue); ok {
+		seen := make(map[string]bool)
+		for _, field := range object.Fields {
+			name := field.Name.Name.Name
+			if seen[name] {
+				v.addErr("UniqueInputFieldNames", field.Name.Loc, "There can be only one input field named %q.", name)
+			}
+			seen[name] = true
+		}
+	}
+	if typ == nil {
+		v.collectVariables(value)
+		return
+	}
+
+	if nonNull, ok := typ.(*internal.NonNull); ok {
+		if _, ok := value.(*ast.NullValue); ok {
+			v.addErr("ValuesOfCorrectType", value.Location(), "Expected value of type %q, found null.", typ)
+			return
+		}
+		typ = nonNull.Type
+	}
+	if _, ok := value.(*ast.NullValue); ok {
+		return
+	}
+
+	switch typ := typ.(type) {
+	case *internal.List:
+		if list, ok := value.(*ast.ListValue); ok {
+			for _, item := range list.Values {
+				v.validateValue(item, typ.Type, false)
+			}
+			return
+		}
+		// a single value is coerced to a list of one item
+		v.validateValue(value, typ.Type, false)
+
+	case *internal.InputObject:
+		object, ok := value.(*ast.ObjectValue)
+		if !ok {
+			v.addErr("ValuesOfCorrectType", value.Location(), "Expected value of type %q, found %s.", typ, printValue(value))
+			v.collectVariables(value)
+			return
+		}
+		given := make(map[string]bool)
+		for _, field := range object.Fields {
+			name := field.Name.Name.Name
+			given[name] = true
+			definition, ok := typ.Fields[name]
+			if !ok {
+				v.addErr("ValuesOfCorrectType", field.Loc, "Field %q is not defined by type %q.", name, typ)
+				v.collectVariables(field.Value)
+				continue
+			}
+			v.validateValue(field.Value, definition.Type, definition.DefaultValue != nil)
+		}
+		for _, name := range sortedKeys(typ.Fields) {
+			definition := typ.Fields[name]
+			if _, ok := definition.Type.(*internal.NonNull); ok && definition.DefaultValue == nil && !given[name] {
+				v.addErr("ValuesOfCorrectType", object.Loc, "Field \"%s.%s\" of required type %q was not provided.", typ, name, definition.Type)
+			}
+		}
+
+	case *internal.Enum:
+		enum, ok := value.(*ast.EnumValue)
+		if !ok {
+			v.addErr("ValuesOfCorrectType", value.Location(), "Enum %q cannot represent non-enum value: %s.", typ, printValue(value))
+			v.collectVariables(value)
+			return
+		}
+		if _, ok := typ.ReverseMap[enum.Value]; !ok && !contains(typ.Values, enum.Value) {
+			v.addErr("ValuesOfCorrectType", value.Location(), "Value %q does not exist in %q enum.", enum.Value, typ)
+		}
+
+	case *internal.Scalar:
+		if !validScalar(typ, value) {
+			v.addErr("ValuesOfCorrectType", value.Location(), "Expected value of type %q, found %s.", typ, printValue(value))
+		}
+		v.collectVariables(value)
+	}
+}
+
+// collectVariables records the variables used in value, in positions of unknown type.
+func (v *validator) collectVariables(value ast.Value) {
+	switch value := value.(type) {
+	case *ast.Variable:
+		v.scope.variables = append(v.scope.variables, &variableUsage{variable: value})
+	case *ast.ListValue:
+		for _, item := range value.Values {
+			v.collectVariables(item)
+		}
+	case *ast.ObjectValue:
+		for _, field := range value.Fields {
+			v.collectVariables(field.Value)
+		}
+	}
+}
+
+// validScalar reports whether value is a literal of the scalar. The scalars of the spec
+// are checked as it defines them, the others accept what their parse functions accept.
+func validScalar(scalar *internal.Scalar, value ast.Value) bool {
+	switch scalar.Name {
+	case "Int":
+		v, ok := value.(*ast.IntValue)
+		if !ok {
+			return false
+		}
+		i, err := strconv.ParseInt(v.Value, 10, 64)
+		return err == nil && i >= math.MinInt32 && i <= math.MaxInt32
+	case "Float":
+		switch value.(type) {
+		case *ast.IntValue, *ast.FloatValue:
+			return true
+		}
+		return false
+	case "String":
+		_, ok := value.(*ast.StringValue)
+		return ok
+	case "Boolean":
+		_, ok := value.(*ast.BooleanValue)
+		return ok
+	case "ID":
+		switch value.(type) {
+		case *ast.IntValue, *ast.StringValue:
+			return true
+		}
+		return false
+	}
+	// the variables inside a literal are only known at execution
+	if hasVariables(value) {
+		return true
+	}
+	if scalar.ParseLiteral != nil {
+		return scalar.ParseLiteral(value) == nil
+	}
+	if scalar.ParseValue != nil {
+		json, err := internal.ValueToJson(value, nil)
+		if err != nil {
+			return false
+		}
+		_, parseErr := scalar.ParseValue(json)
+		return parseErr == nil
+	}
+	return true
+}
+
+func hasVariables(value ast.Value) bool {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return true
+	case *ast.ListValue:
+		for _, item := range value.Values {
+			if hasVariables(item) {
+				return true
+			}
+		}
+	case *ast.ObjectValue:
+		for _, field := range value.Fields {
+			if hasVariables(field.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printValue prints value as it is written in queries, for error messages.
+func printValue(value ast.Value) string {
+	switch value := value.(type) {
+	case *ast.Variable:
+		return "$" + value.Name.Name
+	case *ast.IntValue:
+		return value.Value
+	case *ast.FloatValue:
+		return value.Value
+	case *ast.StringValue:
+		return strconv.Quote(value.Value)
+	case *ast.BooleanValue:
+		return strconv.FormatBool(value.Value)
+	case *ast.NullValue:
+		return "null"
+	case *ast.EnumValue:
+		return value.Value
+	case *ast.ListValue:
+		items := make([]string, len(value.Values))
+		for i, item := range value.Values {
+			items[i] = printValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *ast.ObjectValue:
+		fields := make([]string, len(value.Fields))
+		for i, field := range value.Fields {
+			fields[i] = field.Name.Name.Name + ": " + printValue(field.Value)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return fmt.Sprint(value.GetValue())
+}
+
+// validateVariables checks the variables defined by op against the variables used by it
+// and by the fragments it spreads, directly or not.
+func (v *validator) validateVariables(op *ast.OperationDefinition) {
+	operation := v.operationScopes[op]
+	scopes := []*scope{operation}
+	visited := make(map[string]bool)
+	for i := 0; i < len(scopes); i++ {
+		for _, spread := range scopes[i].spreads {
+			name := spread.Name.Name
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			if fragment, ok := v.fragmentScopes[name]; ok {
+				scopes = append(scopes, fragment)
+			}
+		}
+	}
+
+	defined := make(map[string]*ast.VariableDefinition)
+	for _, s := range scopes {
+		for _, definition := range s.defined {
+			if _, ok := defined[definition.Var.Name.Name]; !ok {
+				defined[definition.Var.Name.Name] = definition
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, s := range scopes {
+		for _, usage := range s.variables {
+			name := usage.variable.Name.Name
+			used[name] = true
+			definition, ok := defined[name]
+			if !ok {
+				if op.Name != nil {
+					v.addErr("NoUndefinedVariables", usage.variable.Loc, "Variable \"$%s\" is not defined by operation %q.", name, op.Name.Name)
+				} else {
+					v.addErr("NoUndefinedVariables", usage.variable.Loc, "Variable \"$%s\" is not defined.", name)
+				}
+				continue
+			}
+			varType := v.typeFromAst(definition.Type)
+			if usage.typ == nil || varType == nil {
+				continue
+			}
+			if !allowedPosition(varType, definition.DefaultValue, usage) {
+				err := v.addErr("VariablesInAllowedPosition", definition.Loc,
+					"Variable \"$%s\" of type %q used in position expecting type %q.", name, varType, usage.typ)
+				err.Locations = append(err.Locations, usage.variable.Loc)
+			}
+		}
+	}
+
+	for _, definition := range op.Vars {
+		if name := definition.Var.Name.Name; !used[name] {
+			if op.Name != nil {
+				v.addErr("NoUnusedVariables", definition.Loc, "Variable \"$%s\" is never used in operation %q.", name, op.Name.Name)
+			} else {
+				v.addErr("NoUnusedVariables", definition.Loc, "Variable \"$%s\" is never used.", name)
+			}
+		}
+	}
+}
+
+// allowedPosition reports whether a variable of type varType can be used where usage is.
+// A nullable variable can be given to a non-null position with a default value, when
+// either the variable or the position has a non-null default.
+func allowedPosition(varType internal.Type, defaultValue ast.Value, usage *variableUsage) bool {
+	if nonNull, ok := usage.typ.(*internal.NonNull); ok {
+		if _, ok := varType.(*internal.NonNull); !ok {
+			_, nullDefault := defaultValue.(*ast.NullValue)
+			if !(defaultValue != nil && !nullDefault) && !usage.hasDefault {
+				return false
+			}
+			return isSubType(varType, nonNull.Type)
+		}
+	}
+	return isSubType(varType, usage.typ)
+}
+
+// isSubType reports whether a value of type sub is always a valid value of type super.
+func isSubType(sub, super internal.Type) bool {
+	if superNonNull, ok := super.(*internal.NonNull); ok {
+		if subNonNull, ok := sub.(*internal.NonNull); ok {
+			return isSubType(subNonNull.Type, superNonNull.Type)
+		}
+		return false
+	}
+	if subNonNull, ok := sub.(*internal.NonNull); ok {
+		return isSubType(subNonNull.Type, super)
+	}
+	if superList, ok := super.(*internal.List); ok {
+		if subList, ok := sub.(*internal.List); ok {
+			return isSubType(subList.Type, superList.Type)
+		}
+		return false
+	}
+	if _, ok := sub.(*internal.List); ok {
+		return false
+	}
+	return sub.String() == super.String()
+}
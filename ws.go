@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/shyptr/graphql/ast"
 	errors2 "github.com/shyptr/graphql/errors"
 	"github.com/shyptr/graphql/execution"
 	"github.com/shyptr/graphql/internal"
 	"github.com/shyptr/graphql/schemabuilder"
+	"github.com/shyptr/graphql/validation"
 	"log"
 	"net/http"
 	"strings"
@@ -18,19 +20,18 @@ import (
 	"gocloud.dev/pubsub"
 )
 
-// HTTPSubHandler implements the handler required for executing the graphql subscriptions
-func HTTPSubHandler(schema *internal.Schema, s *pubsub.Subscription) (http.Handler, func()) {
+// HTTPSubHandler implements the handler required for executing the graphql subscriptions,
+// the queries and mutations are served by a HTTPHandler with the same options.
+func HTTPSubHandler(schema *internal.Schema, s *pubsub.Subscription, opts ...Option) (http.Handler, func()) {
 	source := make(chan *event)
 	sessions := &sessions{
 		data:  map[string][]chan *event{},
 		chans: map[string][]chan struct{}{},
 	}
+	qmHandler := HTTPHandler(schema, opts...).(*Handler)
 	return &httpSubHandler{
-			Handler: Handler{
-				Schema:   schema,
-				Executor: &execution.Executor{},
-			},
-			qmHandler: HTTPHandler(schema),
+			Handler:   *qmHandler,
+			qmHandler: qmHandler,
 			upgrader:  &websocket.Upgrader{},
 			source:    source,
 			sessions:  sessions,
@@ -174,15 +175,23 @@ loop:
 				return
 			}
 			schema := h.Schema.Subscription
-			//if err := validation.Validate(h.Schema, query, gql.Variables, 50); err != nil {
-			//	if er := writeResponse(conn, "error", data.Id, nil, err); er != nil {
-			//		fmt.Println(er)
-			//		return
-			//	}
-			//	fmt.Println(err)
-			//	return
-			//}
-			_, selectionSet, err := execution.ApplySelectionSet(h.Schema, query, "subscription", gql.Variables)
+			if err := execution.CheckLimits(query, gql.OpName, h.config().limits()); err != nil {
+				if er := writeResponse(conn, "error", data.Id, nil, err); er != nil {
+					fmt.Println(er)
+					return
+				}
+				fmt.Println(err)
+				return
+			}
+			if errs := validation.Validate(h.Schema, query); len(errs) > 0 {
+				if er := writeResponse(conn, "error", data.Id, nil, errs); er != nil {
+					fmt.Println(er)
+					return
+				}
+				fmt.Println(errs)
+				return
+			}
+			_, selectionSet, err := execution.ApplySelectionSetWithLimits(h.Schema, query, gql.OpName, gql.Variables, h.config().limits())
 			if err != nil {
 				if er := writeResponse(conn, "error", data.Id, nil, err); er != nil {
 					fmt.Println(er)
@@ -250,7 +259,12 @@ type webConn struct {
 func writeResponse(w *webConn, typ, id string, r interface{}, er error) error {
 	var payload []byte
 	var err error
-	if typ == "data" {
+	if res, ok := r.(*Response); ok && typ == "data" {
+		payload, err = json.Marshal(res)
+		if err != nil {
+			return err
+		}
+	} else if typ == "data" {
 		if er != nil {
 			payload, err = json.Marshal(Response{Data: r, Errors: errors2.MultiError{errors2.New(er.Error())}})
 			if err != nil {
@@ -311,14 +325,33 @@ func (h *httpSubHandler) serveHTTP(conn *webConn, data wsMessage, schema interna
 			return nil
 		default:
 			if err := func() error {
-				res, err := h.Executor.Execute(r.Context(), schema, &schemabuilder.Subscription{msg.payload}, query)
-				rer := err
-				if err := writeResponse(conn, "data", data.Id, res, rer); err != nil {
-					return err
+				// the payloads of @defer and @stream follow the event as more data messages
+				res, errs, incremental := h.Executor.ExecuteIncremental(r.Context(), ast.Subscription, schema,
+					&schemabuilder.Subscription{Payload: msg.payload}, query)
+				initial := &Response{Data: res, Errors: errs}
+				if incremental != nil {
+					hasNext := true
+					initial.HasNext = &hasNext
 				}
-				if rer != nil {
+				h.present(r.Context(), initial)
+				if err := writeResponse(conn, "data", data.Id, initial, nil); err != nil {
+					if incremental != nil {
+						for range incremental {
+						}
+					}
 					return err
 				}
+				if incremental != nil {
+					if err := deliverIncremental(incremental, func(res *Response) error {
+						h.present(r.Context(), res)
+						return writeResponse(conn, "data", data.Id, res, nil)
+					}, nil); err != nil {
+						return err
+					}
+				}
+				if len(errs) > 0 {
+					return errs
+				}
 				return nil
 			}(); err != nil {
 				cls(h.sessions, sid)